@@ -12,6 +12,9 @@ import (
 
 	"github.com/tarantool/tarantool-operator/pkg/apis"
 	"github.com/tarantool/tarantool-operator/pkg/controller"
+	"github.com/tarantool/tarantool-operator/pkg/controller/cluster"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/webhook"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -23,6 +26,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // Change below variables to serve metrics on different host or port.
@@ -30,6 +34,12 @@ var (
 	metricsHost       = "0.0.0.0"
 	metricsPort int32 = 8383
 )
+
+// Change below variables to serve webhooks on different port or keep certificates elsewhere.
+var (
+	webhookPort    int32 = 9876
+	webhookCertDir       = "/tmp/tarantool-operator-webhook"
+)
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -47,6 +57,11 @@ func main() {
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
+	pflag.DurationVar(&cluster.HealthCheckPeriod, "health-check-period", cluster.HealthCheckPeriod, "How often Cartridge is polled for instance health")
+	pflag.StringVar(&tarantool.DefaultClusterDomain, "cluster-domain", tarantool.DefaultClusterDomain, "DNS domain of the Kubernetes cluster, used when Cluster does not set its own")
+	pflag.Int32Var(&tarantool.DefaultBinaryPort, "binary-port", tarantool.DefaultBinaryPort, "Port Tarantool instances advertise, used when Cluster does not set its own")
+	pflag.Int32Var(&tarantool.DefaultHTTPPort, "http-port", tarantool.DefaultHTTPPort, "Port of the Cartridge admin API, used when Cluster does not set its own")
+
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -109,6 +124,33 @@ func main() {
 		os.Exit(1)
 	}
 
+	operatorNamespace, err := k8sutil.GetOperatorNamespace()
+	if err != nil {
+		log.Info("Failed to get operator namespace, using watch namespace", "error", err.Error())
+		operatorNamespace = namespace
+	}
+
+	// Setup webhook server, it provisions its certificate, Service and webhook configurations
+	err = webhook.AddToManager(mgr, crwebhook.ServerOptions{
+		Port:    webhookPort,
+		CertDir: webhookCertDir,
+		BootstrapOptions: &crwebhook.BootstrapOptions{
+			MutatingWebhookConfigName:   "tarantool-operator-mutating",
+			ValidatingWebhookConfigName: "tarantool-operator-validating",
+			Service: &crwebhook.Service{
+				Name:      "tarantool-operator-webhook",
+				Namespace: operatorNamespace,
+				Selectors: map[string]string{
+					"name": "tarantool-operator",
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// // Create Service object to expose the metrics port.
 	// _, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	// if err != nil {
@@ -87,6 +87,8 @@ local ok, err = cartridge.cfg({
 
 assert(ok, tostring(err))
 
+require('key-value.memtx').init()
+
 if console_sock ~= nil then
     console.listen('unix/:' .. console_sock)
 end
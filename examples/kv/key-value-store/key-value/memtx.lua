@@ -0,0 +1,43 @@
+#!/usr/bin/env tarantool
+
+-- set_memtx_memory GraphQL mutation lets tarantool-operator grow
+-- memtx_memory of a running instance without restarting it
+
+local graphql = require('cartridge.graphql')
+local types = require('cartridge.graphql.types')
+local pool = require('cartridge.pool')
+local confapplier = require('cartridge.confapplier')
+
+local function set_memtx_memory(_, args)
+    local topology = confapplier.get_readonly('topology')
+    local server = topology and topology.servers[args.uuid]
+    if server == nil or server == 'expelled' then
+        error(string.format('server %s not found', args.uuid), 0)
+    end
+
+    local conn, err = pool.connect(server.uri)
+    if conn == nil then
+        error(err, 0)
+    end
+
+    conn:eval('box.cfg({memtx_memory = ...})', {args.memtx_memory})
+
+    return true
+end
+
+local function init()
+    graphql.add_mutation({
+        name = 'set_memtx_memory',
+        args = {
+            uuid = types.string.nonNull,
+            memtx_memory = types.long.nonNull,
+        },
+        kind = types.boolean.nonNull,
+        callback = 'key-value.memtx.set_memtx_memory',
+    })
+end
+
+return {
+    init = init,
+    set_memtx_memory = set_memtx_memory,
+}
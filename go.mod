@@ -4,12 +4,11 @@ require (
 	github.com/ghodss/yaml v1.0.0
 	github.com/go-openapi/spec v0.19.0
 	github.com/google/uuid v1.1.1
-	github.com/machinebox/graphql v0.2.2
-	github.com/matryer/is v1.2.0 // indirect
 	github.com/operator-framework/operator-sdk v0.9.1-0.20190802152409-7104d8d7d0e8
 	github.com/prometheus/client_golang v0.9.3-0.20190127221311-3c4408c8b829
 	github.com/spf13/pflag v1.0.3
 	k8s.io/api v0.0.0-20190612125737-db0771252981
+	k8s.io/apiextensions-apiserver v0.0.0-20190228180357-d002e88f6236
 	k8s.io/apimachinery v0.0.0-20190612125636-6a5db36e93ad
 	k8s.io/client-go v11.0.0+incompatible
 	k8s.io/code-generator v0.0.0-20181203235156-f8cba74510f3
@@ -21,7 +20,6 @@ require (
 
 // Pinned to kubernetes-1.13.1
 replace (
-
 	// autoneg is a dependency of early versions of operator-sdk (pre v1.0).
 	// it is no longer hosted on bitbucket.org, so the files are checked in locally and replaced here.
 	bitbucket.org/ww/goautoneg => ./ext/bitbucket.org/ww/autoneg
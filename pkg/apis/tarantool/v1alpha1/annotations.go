@@ -0,0 +1,24 @@
+package v1alpha1
+
+// Role metadata keys v1alpha2 typed fields are stored in
+const (
+	// RolesToAssignAnnotation is a JSON list (or a single JSON string) of cartridge roles
+	RolesToAssignAnnotation = "tarantool.io/rolesToAssign"
+	// VshardGroupNameAnnotation is a vshard group of Role replicasets,
+	// also read from the label of the same name
+	VshardGroupNameAnnotation = "tarantool.io/vshardGroupName"
+	// ReplicasetWeightAnnotation is a vshard weight of Role replicasets
+	ReplicasetWeightAnnotation = "tarantool.io/replicaset-weight"
+	// AllRWAnnotation makes all instances of Role replicasets writable
+	AllRWAnnotation = "tarantool.io/allRW"
+	// PrivilegedAnnotation runs the Tarantool container of Role pods privileged
+	PrivilegedAnnotation = "tarantool.io/privileged"
+)
+
+// Role annotations failover was configured with before Cluster spec.failover
+const (
+	// FailoverModeAnnotation is eventual, stateful-tarantool or stateful-etcd2
+	FailoverModeAnnotation = "tarantool.io/failoverMode"
+	// FailoverSecretAnnotation names the Secret stateful-etcd2 mode takes etcd2 parameters from
+	FailoverSecretAnnotation = "tarantool.io/failoverSecret"
+)
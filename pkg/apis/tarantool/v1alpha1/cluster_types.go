@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -14,15 +15,226 @@ type ClusterSpec struct {
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Failover is the cartridge failover configuration kept in sync by the operator
+	Failover *FailoverSpec `json:"failover,omitempty"`
+	// Domain is the DNS domain of the Kubernetes cluster, operator default if empty
+	Domain string `json:"domain,omitempty"`
+	// BinaryPort is the port instances listen and advertise on, operator default if empty
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	BinaryPort int32 `json:"binaryPort,omitempty"`
+	// HTTPPort is the port of the cartridge admin API, operator default if empty
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HTTPPort int32 `json:"httpPort,omitempty"`
+	// AdminAPI configures access to the cartridge admin API
+	AdminAPI *AdminAPISpec `json:"adminAPI,omitempty"`
+	// Topology selects the backend managing the cluster topology
+	Topology *TopologySpec `json:"topology,omitempty"`
+	// GrowMemtxAtRuntime applies larger memtx_memory to running instances
+	// through the set_memtx_memory mutation the application registers
+	GrowMemtxAtRuntime bool `json:"growMemtxAtRuntime,omitempty"`
+}
+
+// TopologySpec defines the backend managing the cluster topology
+// +k8s:openapi-gen=true
+type TopologySpec struct {
+	// Provider names a registered topology backend, "cartridge" if empty
+	Provider string `json:"provider,omitempty"`
+}
+
+// AdminAPISpec defines how the operator calls the cartridge admin API
+// +k8s:openapi-gen=true
+type AdminAPISpec struct {
+	// Auth enables HTTP basic auth
+	Auth *AdminAuthSpec `json:"auth,omitempty"`
+	// TLS switches admin calls to https
+	TLS *AdminTLSSpec `json:"tls,omitempty"`
+	// Timeouts bound admin calls by operation
+	Timeouts *AdminTimeoutsSpec `json:"timeouts,omitempty"`
+}
+
+// AdminAuthSpec defines admin API credentials
+// +k8s:openapi-gen=true
+type AdminAuthSpec struct {
+	// Username is "admin" if empty, which authenticates with the cluster cookie
+	Username string `json:"username,omitempty"`
+	// PasswordSecretRef selects the cluster cookie or the user password from a Secret
+	PasswordSecretRef corev1.SecretKeySelector `json:"passwordSecretRef"`
+}
+
+// AdminTLSSpec defines how instance certificates are verified and the operator is identified
+// +k8s:openapi-gen=true
+type AdminTLSSpec struct {
+	// SecretName names a Secret with an optional ca.crt bundle and,
+	// for a client certificate, tls.crt and tls.key
+	SecretName string `json:"secretName,omitempty"`
+	// InsecureSkipVerify disables verification of instance certificates
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// AdminTimeoutsSpec defines how many seconds admin calls may take
+// +k8s:openapi-gen=true
+type AdminTimeoutsSpec struct {
+	// Call bounds queries and small changes, 5 if not set
+	// +kubebuilder:validation:Minimum=1
+	Call *int32 `json:"call,omitempty"`
+	// Join bounds joining an instance, 10 if not set
+	// +kubebuilder:validation:Minimum=1
+	Join *int32 `json:"join,omitempty"`
+	// EditTopology bounds topology changes, which wait for every instance to apply them, 60 if not set
+	// +kubebuilder:validation:Minimum=1
+	EditTopology *int32 `json:"editTopology,omitempty"`
+	// BootstrapVshard bounds vshard bootstrap, 60 if not set
+	// +kubebuilder:validation:Minimum=1
+	BootstrapVshard *int32 `json:"bootstrapVshard,omitempty"`
+}
+
+// FailoverSpec defines cartridge failover configuration
+// +k8s:openapi-gen=true
+type FailoverSpec struct {
+	// Mode is one of "disabled", "eventual" or "stateful"
+	// +kubebuilder:validation:Enum=disabled,eventual,stateful
+	Mode string `json:"mode"`
+	// StateProvider is "tarantool" or "etcd2", required by the stateful mode
+	// +kubebuilder:validation:Enum=tarantool,etcd2
+	StateProvider string `json:"stateProvider,omitempty"`
+	// FailoverTimeout is a number of seconds to mark a suspect instance dead
+	FailoverTimeout *int32 `json:"failoverTimeout,omitempty"`
+	// FencingEnabled makes a leader go read-only when it loses both the state provider and replicas
+	FencingEnabled bool `json:"fencingEnabled,omitempty"`
+	// FencingTimeout is a number of seconds to trigger fencing after a connectivity loss
+	FencingTimeout *int32 `json:"fencingTimeout,omitempty"`
+	// FencingPause is a number of seconds between fencing health checks
+	FencingPause *int32 `json:"fencingPause,omitempty"`
+	// Tarantool configures the stateboard state provider
+	Tarantool *TarantoolStateProviderSpec `json:"tarantool,omitempty"`
+	// Etcd2 configures the etcd2 state provider
+	Etcd2 *Etcd2StateProviderSpec `json:"etcd2,omitempty"`
+}
+
+// TarantoolStateProviderSpec defines stateboard connection parameters
+// +k8s:openapi-gen=true
+type TarantoolStateProviderSpec struct {
+	// URI is the stateboard address, host:port
+	URI string `json:"uri"`
+	// PasswordSecretRef selects the stateboard password from a Secret
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+// Etcd2StateProviderSpec defines etcd2 connection parameters
+// +k8s:openapi-gen=true
+type Etcd2StateProviderSpec struct {
+	// Endpoints are etcd client URLs
+	Endpoints []string `json:"endpoints,omitempty"`
+	// Prefix is the etcd key prefix of the cluster
+	Prefix string `json:"prefix,omitempty"`
+	// LockDelay is a number of seconds the leader lock is kept after the coordinator is gone
+	LockDelay *int32 `json:"lockDelay,omitempty"`
+	// Username is the etcd user name
+	Username string `json:"username,omitempty"`
+	// PasswordSecretRef selects the etcd user password from a Secret
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+// ClusterConditionType is a type of Cluster condition
+type ClusterConditionType string
+
+const (
+	// ClusterBootstrapped vshard is bootstrapped on the cluster
+	ClusterBootstrapped ClusterConditionType = "Bootstrapped"
+	// ClusterFailoverConfigured cartridge failover matches spec.failover
+	ClusterFailoverConfigured ClusterConditionType = "FailoverConfigured"
+	// ClusterAllInstancesJoined every pod of the cluster is joined to the topology
+	ClusterAllInstancesJoined ClusterConditionType = "AllInstancesJoined"
+	// ClusterHealthy cartridge reports every server as healthy
+	ClusterHealthy ClusterConditionType = "Healthy"
+	// ClusterTopologyInSync cartridge replicasets match their Roles
+	ClusterTopologyInSync ClusterConditionType = "TopologyInSync"
+)
+
+// ClusterCondition describes the state of a Cluster at a certain point
+// +k8s:openapi-gen=true
+type ClusterCondition struct {
+	// Type of the condition
+	Type ClusterConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition changed its status
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a one-word CamelCase reason for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the transition
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterReplicasetStatus is a replicaset as reported by cartridge
+// +k8s:openapi-gen=true
+type ClusterReplicasetStatus struct {
+	UUID        string   `json:"uuid"`
+	Alias       string   `json:"alias,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Weight      float64  `json:"weight,omitempty"`
+	AllRW       bool     `json:"allRW,omitempty"`
+	VshardGroup string   `json:"vshardGroup,omitempty"`
+	// Drift lists fields which differ from the ones of the Role
+	Drift []string `json:"drift,omitempty"`
+}
+
+// ClusterServerStatus is a server as reported by cartridge
+// +k8s:openapi-gen=true
+type ClusterServerStatus struct {
+	UUID           string `json:"uuid,omitempty"`
+	Alias          string `json:"alias,omitempty"`
+	URI            string `json:"uri"`
+	ReplicasetUUID string `json:"replicasetUUID,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Message        string `json:"message,omitempty"`
 }
 
 // ClusterStatus defines the observed state of Cluster
 // +k8s:openapi-gen=true
 type ClusterStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
-	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+	// State is "Ready" once the cluster is bootstrapped
 	State string `json:"state,omitempty"`
+	// ObservedGeneration is the most recent Cluster generation fully reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Leader is the admin API address of the instance the cluster is managed through
+	Leader string `json:"leader,omitempty"`
+	// Conditions are the latest observations of the Cluster state
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+	// Replicasets are the cluster replicasets as reported by cartridge
+	Replicasets []ClusterReplicasetStatus `json:"replicasets,omitempty"`
+	// Servers are the cluster servers as reported by cartridge
+	Servers []ClusterServerStatus `json:"servers,omitempty"`
+}
+
+// GetCondition returns the condition of the given type, nil if there is none
+func (s *ClusterStatus) GetCondition(conditionType ClusterConditionType) *ClusterCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// SetCondition adds or updates the condition, transition time changes only with the status
+func (s *ClusterStatus) SetCondition(conditionType ClusterConditionType, status corev1.ConditionStatus, reason string, message string) {
+	condition := s.GetCondition(conditionType)
+	if condition == nil {
+		s.Conditions = append(s.Conditions, ClusterCondition{Type: conditionType})
+		condition = &s.Conditions[len(s.Conditions)-1]
+	}
+
+	if condition.Status != status {
+		condition.Status = status
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Reason = reason
+	condition.Message = message
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -12,14 +13,126 @@ type RoleSpec struct {
 	StorageTemplate *ReplicasetTemplate `json:"storageTemplate,omitempty"`
 	// Selector is a LabelSelector to find ReplicasetTemplate resources from which StatefulSet created
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// DeleteVolumeClaims removes PersistentVolumeClaims of StatefulSets deleted on scale down
+	DeleteVolumeClaims bool `json:"deleteVolumeClaims,omitempty"`
+}
+
+// ReplicasetPhase is a lifecycle phase of a StatefulSet (Tarantool replicaset) of Role
+type ReplicasetPhase string
+
+const (
+	// ReplicasetActive replicaset is a regular member of the cluster
+	ReplicasetActive ReplicasetPhase = "Active"
+	// ReplicasetDraining replicaset weight is set to 0, vshard buckets are moving away
+	ReplicasetDraining ReplicasetPhase = "Draining"
+	// ReplicasetExpelling replicaset has no buckets, its instances are being expelled
+	ReplicasetExpelling ReplicasetPhase = "Expelling"
+	// ReplicasetDeleting all replicaset instances are expelled, StatefulSet is being deleted
+	ReplicasetDeleting ReplicasetPhase = "Deleting"
+)
+
+// ReplicasetStatus defines the observed state of a single StatefulSet of Role
+// +k8s:openapi-gen=true
+type ReplicasetStatus struct {
+	// Name is the StatefulSet name
+	Name string `json:"name"`
+	// Phase is the lifecycle phase of the replicaset
+	Phase ReplicasetPhase `json:"phase,omitempty"`
+	// Replicas is the number of instances of the replicaset
+	Replicas int32 `json:"replicas"`
+	// ReadyReplicas is the number of ready instances of the replicaset
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// JoinedReplicas is the number of instances joined to the cluster
+	JoinedReplicas int32 `json:"joinedReplicas"`
+	// CurrentRevision is the StatefulSet revision running pods were created from
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	// UpdateRevision is the StatefulSet revision of the current pod template
+	UpdateRevision string `json:"updateRevision,omitempty"`
+	// UpdatedReplicas is the number of instances running the update revision
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// Rollout is the state of the rolling update, InProgress or Stalled
+	Rollout string `json:"rollout,omitempty"`
+	// RolloutMessage describes the current rolling update step
+	RolloutMessage string `json:"rolloutMessage,omitempty"`
+}
+
+// RoleConditionType is a type of Role condition
+type RoleConditionType string
+
+const (
+	// RoleReady every replicaset is ready and joined
+	RoleReady RoleConditionType = "Ready"
+	// RoleScaling the number of replicasets differs from spec.numReplicasets
+	RoleScaling RoleConditionType = "Scaling"
+	// RoleTemplateFound spec.selector matches a ReplicasetTemplate
+	RoleTemplateFound RoleConditionType = "TemplateFound"
+	// RoleRollingUpdate instances of some replicaset are being restarted
+	// to pick up the current pod template
+	RoleRollingUpdate RoleConditionType = "RollingUpdate"
+	// RolePodsRejected pods of some replicaset could not be created,
+	// admission (e.g. Pod Security Standards) rejects them
+	RolePodsRejected RoleConditionType = "PodsRejected"
+)
+
+// RoleCondition describes the state of a Role at a certain point
+// +k8s:openapi-gen=true
+type RoleCondition struct {
+	// Type of the condition
+	Type RoleConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition changed its status
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a one-word CamelCase reason for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the transition
+	Message string `json:"message,omitempty"`
 }
 
 // RoleStatus defines the observed state of Role
 // +k8s:openapi-gen=true
 type RoleStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
-	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+	// NumReplicasets is the number of StatefulSets existing under this Role
+	NumReplicasets int32 `json:"numReplicasets"`
+	// ReadyReplicasets is the number of StatefulSets with all instances ready
+	ReadyReplicasets int32 `json:"readyReplicasets"`
+	// JoinedReplicasets is the number of StatefulSets with all instances joined
+	JoinedReplicasets int32 `json:"joinedReplicasets"`
+	// Selector is the label selector of Role pods, serialized for the scale subresource
+	Selector string `json:"selector,omitempty"`
+	// ObservedGeneration is the most recent Role generation reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the latest observations of the Role state
+	Conditions []RoleCondition `json:"conditions,omitempty"`
+	// Replicasets lists StatefulSets created under this Role
+	Replicasets []ReplicasetStatus `json:"replicasets,omitempty"`
+}
+
+// GetCondition returns the condition of the given type, nil if there is none
+func (s *RoleStatus) GetCondition(conditionType RoleConditionType) *RoleCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// SetCondition adds or updates the condition, transition time changes only with the status
+func (s *RoleStatus) SetCondition(conditionType RoleConditionType, status corev1.ConditionStatus, reason string, message string) {
+	condition := s.GetCondition(conditionType)
+	if condition == nil {
+		s.Conditions = append(s.Conditions, RoleCondition{Type: conditionType})
+		condition = &s.Conditions[len(s.Conditions)-1]
+	}
+
+	if condition.Status != status {
+		condition.Status = status
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Reason = reason
+	condition.Message = message
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -27,7 +140,11 @@ type RoleStatus struct {
 // Role is the Schema for the roles API
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
-// +kubebuilder:subresource:scale:specpath=.spec.numReplicasets,statuspath=.status.numReplicasets
+// +kubebuilder:subresource:scale:specpath=.spec.numReplicasets,statuspath=.status.numReplicasets,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Replicasets",type="integer",JSONPath=".spec.numReplicasets"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicasets"
+// +kubebuilder:printcolumn:name="Joined",type="integer",JSONPath=".status.joinedReplicasets"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type Role struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -13,15 +13,156 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Cluster":                  schema_pkg_apis_tarantool_v1alpha1_Cluster(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterSpec":              schema_pkg_apis_tarantool_v1alpha1_ClusterSpec(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterStatus":            schema_pkg_apis_tarantool_v1alpha1_ClusterStatus(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetTemplate":       schema_pkg_apis_tarantool_v1alpha1_ReplicasetTemplate(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetTemplateSpec":   schema_pkg_apis_tarantool_v1alpha1_ReplicasetTemplateSpec(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetTemplateStatus": schema_pkg_apis_tarantool_v1alpha1_ReplicasetTemplateStatus(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Role":                     schema_pkg_apis_tarantool_v1alpha1_Role(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleSpec":                 schema_pkg_apis_tarantool_v1alpha1_RoleSpec(ref),
-		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleStatus":               schema_pkg_apis_tarantool_v1alpha1_RoleStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminAPISpec":               schema_pkg_apis_tarantool_v1alpha1_AdminAPISpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminAuthSpec":              schema_pkg_apis_tarantool_v1alpha1_AdminAuthSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminTLSSpec":               schema_pkg_apis_tarantool_v1alpha1_AdminTLSSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminTimeoutsSpec":          schema_pkg_apis_tarantool_v1alpha1_AdminTimeoutsSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Cluster":                    schema_pkg_apis_tarantool_v1alpha1_Cluster(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterCondition":           schema_pkg_apis_tarantool_v1alpha1_ClusterCondition(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterReplicasetStatus":    schema_pkg_apis_tarantool_v1alpha1_ClusterReplicasetStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterServerStatus":        schema_pkg_apis_tarantool_v1alpha1_ClusterServerStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterSpec":                schema_pkg_apis_tarantool_v1alpha1_ClusterSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterStatus":              schema_pkg_apis_tarantool_v1alpha1_ClusterStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Etcd2StateProviderSpec":     schema_pkg_apis_tarantool_v1alpha1_Etcd2StateProviderSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.FailoverSpec":               schema_pkg_apis_tarantool_v1alpha1_FailoverSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetStatus":           schema_pkg_apis_tarantool_v1alpha1_ReplicasetStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetTemplate":         schema_pkg_apis_tarantool_v1alpha1_ReplicasetTemplate(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetTemplateSpec":     schema_pkg_apis_tarantool_v1alpha1_ReplicasetTemplateSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetTemplateStatus":   schema_pkg_apis_tarantool_v1alpha1_ReplicasetTemplateStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Role":                       schema_pkg_apis_tarantool_v1alpha1_Role(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleCondition":              schema_pkg_apis_tarantool_v1alpha1_RoleCondition(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleSpec":                   schema_pkg_apis_tarantool_v1alpha1_RoleSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleStatus":                 schema_pkg_apis_tarantool_v1alpha1_RoleStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.TarantoolStateProviderSpec": schema_pkg_apis_tarantool_v1alpha1_TarantoolStateProviderSpec(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.TopologySpec":               schema_pkg_apis_tarantool_v1alpha1_TopologySpec(ref),
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_AdminAPISpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AdminAPISpec defines how the operator calls the cartridge admin API",
+				Properties: map[string]spec.Schema{
+					"auth": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Auth enables HTTP basic auth",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminAuthSpec"),
+						},
+					},
+					"tls": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLS switches admin calls to https",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminTLSSpec"),
+						},
+					},
+					"timeouts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timeouts bound admin calls by operation",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminTimeoutsSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminAuthSpec", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminTLSSpec", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminTimeoutsSpec"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_AdminAuthSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AdminAuthSpec defines admin API credentials",
+				Properties: map[string]spec.Schema{
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Username is \"admin\" if empty, which authenticates with the cluster cookie",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordSecretRef selects the cluster cookie or the user password from a Secret",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"passwordSecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_AdminTLSSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AdminTLSSpec defines how instance certificates are verified and the operator is identified",
+				Properties: map[string]spec.Schema{
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecretName names a Secret with an optional ca.crt bundle and, for a client certificate, tls.crt and tls.key",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"insecureSkipVerify": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InsecureSkipVerify disables verification of instance certificates",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_AdminTimeoutsSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AdminTimeoutsSpec defines how many seconds admin calls may take",
+				Properties: map[string]spec.Schema{
+					"call": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Call bounds queries and small changes, 5 if not set",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"join": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Join bounds joining an instance, 10 if not set",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"editTopology": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EditTopology bounds topology changes, which wait for every instance to apply them, 60 if not set",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"bootstrapVshard": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BootstrapVshard bounds vshard bootstrap, 60 if not set",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
 	}
 }
 
@@ -68,6 +209,182 @@ func schema_pkg_apis_tarantool_v1alpha1_Cluster(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_pkg_apis_tarantool_v1alpha1_ClusterCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ClusterCondition describes the state of a Cluster at a certain point",
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the condition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of the condition, one of True, False, Unknown",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastTransitionTime is the last time the condition changed its status",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a one-word CamelCase reason for the condition's last transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_ClusterReplicasetStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ClusterReplicasetStatus is a replicaset as reported by cartridge",
+				Properties: map[string]spec.Schema{
+					"uuid": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"alias": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"roles": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"weight": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"allRW": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"vshardGroup": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"drift": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Drift lists fields which differ from the ones of the Role",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"uuid"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_ClusterServerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ClusterServerStatus is a server as reported by cartridge",
+				Properties: map[string]spec.Schema{
+					"uuid": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"alias": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"uri": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"replicasetUUID": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"uri"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_tarantool_v1alpha1_ClusterSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -80,11 +397,57 @@ func schema_pkg_apis_tarantool_v1alpha1_ClusterSpec(ref common.ReferenceCallback
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
 						},
 					},
+					"failover": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Failover is the cartridge failover configuration kept in sync by the operator",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.FailoverSpec"),
+						},
+					},
+					"domain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Domain is the DNS domain of the Kubernetes cluster, operator default if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"binaryPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BinaryPort is the port instances listen and advertise on, operator default if empty",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"httpPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HTTPPort is the port of the cartridge admin API, operator default if empty",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"adminAPI": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AdminAPI configures access to the cartridge admin API",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminAPISpec"),
+						},
+					},
+					"topology": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Topology selects the backend managing the cluster topology",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.TopologySpec"),
+						},
+					},
+					"growMemtxAtRuntime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GrowMemtxAtRuntime applies larger memtx_memory to running instances through the set_memtx_memory mutation the application registers",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.AdminAPISpec", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.FailoverSpec", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.TopologySpec", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
@@ -96,14 +459,276 @@ func schema_pkg_apis_tarantool_v1alpha1_ClusterStatus(ref common.ReferenceCallba
 				Properties: map[string]spec.Schema{
 					"state": {
 						SchemaProps: spec.SchemaProps{
-							Description: "INSERT ADDITIONAL STATUS FIELD - define observed state of cluster Important: Run \"operator-sdk generate k8s\" to regenerate code after modifying this file Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html",
+							Description: "State is \"Ready\" once the cluster is bootstrapped",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObservedGeneration is the most recent Cluster generation fully reconciled",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"leader": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Leader is the admin API address of the instance the cluster is managed through",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions are the latest observations of the Cluster state",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterCondition"),
+									},
+								},
+							},
+						},
+					},
+					"replicasets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replicasets are the cluster replicasets as reported by cartridge",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterReplicasetStatus"),
+									},
+								},
+							},
+						},
+					},
+					"servers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Servers are the cluster servers as reported by cartridge",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterServerStatus"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterCondition", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterReplicasetStatus", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ClusterServerStatus"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_Etcd2StateProviderSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Etcd2StateProviderSpec defines etcd2 connection parameters",
+				Properties: map[string]spec.Schema{
+					"endpoints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoints are etcd client URLs",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"prefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Prefix is the etcd key prefix of the cluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lockDelay": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LockDelay is a number of seconds the leader lock is kept after the coordinator is gone",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Username is the etcd user name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordSecretRef selects the etcd user password from a Secret",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_FailoverSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FailoverSpec defines cartridge failover configuration",
+				Properties: map[string]spec.Schema{
+					"mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mode is one of \"disabled\", \"eventual\" or \"stateful\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"stateProvider": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StateProvider is \"tarantool\" or \"etcd2\", required by the stateful mode",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"failoverTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailoverTimeout is a number of seconds to mark a suspect instance dead",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"fencingEnabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FencingEnabled makes a leader go read-only when it loses both the state provider and replicas",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"fencingTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FencingTimeout is a number of seconds to trigger fencing after a connectivity loss",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"fencingPause": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FencingPause is a number of seconds between fencing health checks",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"tarantool": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tarantool configures the stateboard state provider",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.TarantoolStateProviderSpec"),
+						},
+					},
+					"etcd2": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Etcd2 configures the etcd2 state provider",
+							Ref:         ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Etcd2StateProviderSpec"),
+						},
+					},
+				},
+				Required: []string{"mode"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.Etcd2StateProviderSpec", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.TarantoolStateProviderSpec"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_ReplicasetStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ReplicasetStatus defines the observed state of a single StatefulSet of Role",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the StatefulSet name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase is the lifecycle phase of the replicaset",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replicas is the number of instances of the replicaset",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"readyReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadyReplicas is the number of ready instances of the replicaset",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"joinedReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JoinedReplicas is the number of instances joined to the cluster",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"currentRevision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CurrentRevision is the StatefulSet revision running pods were created from",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"updateRevision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpdateRevision is the StatefulSet revision of the current pod template",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"updatedReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpdatedReplicas is the number of instances running the update revision",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rollout is the state of the rolling update, InProgress or Stalled",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rolloutMessage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RolloutMessage describes the current rolling update step",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "replicas", "readyReplicas", "joinedReplicas", "updatedReplicas"},
+			},
+		},
 		Dependencies: []string{},
 	}
 }
@@ -218,6 +843,55 @@ func schema_pkg_apis_tarantool_v1alpha1_Role(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_tarantool_v1alpha1_RoleCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RoleCondition describes the state of a Role at a certain point",
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the condition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of the condition, one of True, False, Unknown",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastTransitionTime is the last time the condition changed its status",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a one-word CamelCase reason for the condition's last transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_tarantool_v1alpha1_RoleSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -242,6 +916,13 @@ func schema_pkg_apis_tarantool_v1alpha1_RoleSpec(ref common.ReferenceCallback) c
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
 						},
 					},
+					"deleteVolumeClaims": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteVolumeClaims removes PersistentVolumeClaims of StatefulSets deleted on scale down",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -255,7 +936,119 @@ func schema_pkg_apis_tarantool_v1alpha1_RoleStatus(ref common.ReferenceCallback)
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Description: "RoleStatus defines the observed state of Role",
-				Properties:  map[string]spec.Schema{},
+				Properties: map[string]spec.Schema{
+					"numReplicasets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NumReplicasets is the number of StatefulSets existing under this Role",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"readyReplicasets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadyReplicasets is the number of StatefulSets with all instances ready",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"joinedReplicasets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JoinedReplicasets is the number of StatefulSets with all instances joined",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector is the label selector of Role pods, serialized for the scale subresource",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObservedGeneration is the most recent Role generation reconciled",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions are the latest observations of the Role state",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleCondition"),
+									},
+								},
+							},
+						},
+					},
+					"replicasets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replicasets lists StatefulSets created under this Role",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"numReplicasets", "readyReplicasets", "joinedReplicasets"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.ReplicasetStatus", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleCondition"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_TarantoolStateProviderSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TarantoolStateProviderSpec defines stateboard connection parameters",
+				Properties: map[string]spec.Schema{
+					"uri": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URI is the stateboard address, host:port",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordSecretRef selects the stateboard password from a Secret",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"uri"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha1_TopologySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TopologySpec defines the backend managing the cluster topology",
+				Properties: map[string]spec.Schema{
+					"provider": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Provider names a registered topology backend, \"cartridge\" if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
 			},
 		},
 		Dependencies: []string{},
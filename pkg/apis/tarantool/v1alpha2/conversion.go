@@ -0,0 +1,162 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+)
+
+// v1alpha1 is the storage version, v1alpha2 Role typed fields are kept
+// in v1alpha1 Role annotations so controllers and existing clusters keep working
+
+// vshardGroupFromLabelAnnotation marks vshardGroup taken from the Role label,
+// it is not written back as an annotation unless it is changed, as the
+// annotation changes pod templates of the Role
+const vshardGroupFromLabelAnnotation = "tarantool.io/vshardGroupFromLabel"
+
+// ConvertTo converts this Role to the v1alpha1 storage version
+func (in *Role) ConvertTo(out *v1alpha1.Role) error {
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Spec = v1alpha1.RoleSpec{
+		NumReplicasets:     in.Spec.NumReplicasets,
+		Selector:           in.Spec.Selector.DeepCopy(),
+		DeleteVolumeClaims: in.Spec.DeleteVolumeClaims,
+	}
+	out.Status = *in.Status.DeepCopy()
+
+	annotations := out.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if len(in.Spec.CartridgeRoles) > 0 {
+		roles, err := json.Marshal(in.Spec.CartridgeRoles)
+		if err != nil {
+			return err
+		}
+		annotations[v1alpha1.RolesToAssignAnnotation] = string(roles)
+	}
+	_, fromLabel := annotations[vshardGroupFromLabelAnnotation]
+	delete(annotations, vshardGroupFromLabelAnnotation)
+	if in.Spec.VshardGroup != "" && !(fromLabel && in.Spec.VshardGroup == in.GetLabels()[v1alpha1.VshardGroupNameAnnotation]) {
+		annotations[v1alpha1.VshardGroupNameAnnotation] = in.Spec.VshardGroup
+	}
+	if in.Spec.Weight != nil {
+		annotations[v1alpha1.ReplicasetWeightAnnotation] = strconv.Itoa(int(*in.Spec.Weight))
+	}
+	if in.Spec.AllRW {
+		annotations[v1alpha1.AllRWAnnotation] = "true"
+	}
+	if in.Spec.Privileged {
+		annotations[v1alpha1.PrivilegedAnnotation] = "true"
+	}
+
+	if len(annotations) > 0 {
+		out.SetAnnotations(annotations)
+	} else {
+		out.SetAnnotations(nil)
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 storage version to this Role,
+// annotations which could not be parsed are left in place
+func (in *Role) ConvertFrom(src *v1alpha1.Role) error {
+	in.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	in.Spec = RoleSpec{
+		NumReplicasets:     src.Spec.NumReplicasets,
+		Selector:           src.Spec.Selector.DeepCopy(),
+		DeleteVolumeClaims: src.Spec.DeleteVolumeClaims,
+	}
+	in.Status = *src.Status.DeepCopy()
+
+	annotations := in.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if val, ok := annotations[v1alpha1.RolesToAssignAnnotation]; ok {
+		if roles, err := ParseCartridgeRoles(val); err == nil {
+			in.Spec.CartridgeRoles = roles
+			delete(annotations, v1alpha1.RolesToAssignAnnotation)
+		}
+	}
+
+	if val, ok := annotations[v1alpha1.VshardGroupNameAnnotation]; ok {
+		in.Spec.VshardGroup = val
+		delete(annotations, v1alpha1.VshardGroupNameAnnotation)
+	} else if val, ok := in.GetLabels()[v1alpha1.VshardGroupNameAnnotation]; ok {
+		in.Spec.VshardGroup = val
+		annotations[vshardGroupFromLabelAnnotation] = "true"
+	}
+
+	if val, ok := annotations[v1alpha1.ReplicasetWeightAnnotation]; ok {
+		if weight, err := strconv.ParseInt(val, 10, 32); err == nil {
+			w := int32(weight)
+			in.Spec.Weight = &w
+			delete(annotations, v1alpha1.ReplicasetWeightAnnotation)
+		}
+	}
+
+	if val, ok := annotations[v1alpha1.AllRWAnnotation]; ok {
+		if allRW, err := strconv.ParseBool(val); err == nil {
+			in.Spec.AllRW = allRW
+			delete(annotations, v1alpha1.AllRWAnnotation)
+		}
+	}
+
+	if val, ok := annotations[v1alpha1.PrivilegedAnnotation]; ok {
+		if privileged, err := strconv.ParseBool(val); err == nil {
+			in.Spec.Privileged = privileged
+			delete(annotations, v1alpha1.PrivilegedAnnotation)
+		}
+	}
+
+	if len(annotations) == 0 {
+		in.SetAnnotations(nil)
+	} else {
+		in.SetAnnotations(annotations)
+	}
+
+	return nil
+}
+
+// ParseCartridgeRoles reads cartridge roles in any format tarantool.io/rolesToAssign
+// accepts: a JSON list, a single JSON string or a dot separated label value
+func ParseCartridgeRoles(val string) ([]string, error) {
+	var roles []string
+	if err := json.Unmarshal([]byte(val), &roles); err == nil {
+		return roles, nil
+	}
+
+	var role string
+	if err := json.Unmarshal([]byte(val), &role); err == nil {
+		return []string{role}, nil
+	}
+
+	if val == "" || strings.ContainsAny(val, `"[]{} `) {
+		return nil, fmt.Errorf("failed to parse cartridge roles %q", val)
+	}
+
+	return strings.Split(val, "."), nil
+}
+
+// ConvertTo converts this ReplicasetTemplate to the v1alpha1 storage version
+func (in *ReplicasetTemplate) ConvertTo(out *v1alpha1.ReplicasetTemplate) error {
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Spec = in.Spec.DeepCopy()
+
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 storage version to this ReplicasetTemplate
+func (in *ReplicasetTemplate) ConvertFrom(src *v1alpha1.ReplicasetTemplate) error {
+	in.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	in.Spec = src.Spec.DeepCopy()
+
+	return nil
+}
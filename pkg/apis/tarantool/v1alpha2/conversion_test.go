@@ -0,0 +1,117 @@
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRoleConvertFrom_ParsesAnnotations(t *testing.T) {
+	src := &v1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"tarantool.io/rolesToAssign":     `["vshard-storage", "app.roles.storage"]`,
+				"tarantool.io/vshardGroupName":   "hot",
+				"tarantool.io/replicaset-weight": "50",
+				"tarantool.io/allRW":             "true",
+				"tarantool.io/privileged":        "true",
+				"tarantool.io/failoverMode":      "eventual",
+			},
+		},
+	}
+
+	role := &Role{}
+	if err := role.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(role.Spec.CartridgeRoles, []string{"vshard-storage", "app.roles.storage"}) {
+		t.Fatalf("unexpected cartridge roles %v", role.Spec.CartridgeRoles)
+	}
+	if role.Spec.VshardGroup != "hot" || role.Spec.Weight == nil || *role.Spec.Weight != 50 || !role.Spec.AllRW || !role.Spec.Privileged {
+		t.Fatalf("unexpected spec %+v", role.Spec)
+	}
+	if !reflect.DeepEqual(role.GetAnnotations(), map[string]string{"tarantool.io/failoverMode": "eventual"}) {
+		t.Fatalf("typed annotations must be removed, got %v", role.GetAnnotations())
+	}
+
+	back := &v1alpha1.Role{}
+	if err := role.ConvertTo(back); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"tarantool.io/rolesToAssign":     `["vshard-storage","app.roles.storage"]`,
+		"tarantool.io/vshardGroupName":   "hot",
+		"tarantool.io/replicaset-weight": "50",
+		"tarantool.io/allRW":             "true",
+		"tarantool.io/privileged":        "true",
+		"tarantool.io/failoverMode":      "eventual",
+	}
+	if !reflect.DeepEqual(back.GetAnnotations(), expected) {
+		t.Fatalf("expected annotations %v, got %v", expected, back.GetAnnotations())
+	}
+}
+
+func TestRoleConvert_RoundTripsVshardGroupLabel(t *testing.T) {
+	src := &v1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"tarantool.io/role": "storage", "tarantool.io/vshardGroupName": "hot"},
+		},
+	}
+
+	role := &Role{}
+	if err := role.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+	if role.Spec.VshardGroup != "hot" {
+		t.Fatalf("vshard group must be taken from the label, got %q", role.Spec.VshardGroup)
+	}
+
+	back := &v1alpha1.Role{}
+	if err := role.ConvertTo(back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.ObjectMeta, src.ObjectMeta) {
+		t.Fatalf("round trip must keep the Role as it was, got %+v", back.ObjectMeta)
+	}
+
+	// vshard group changed in v1alpha2 is stored in the annotation
+	role.Spec.VshardGroup = "cold"
+	if err := role.ConvertTo(back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.GetAnnotations(), map[string]string{"tarantool.io/vshardGroupName": "cold"}) {
+		t.Fatalf("expected changed vshard group annotation, got %v", back.GetAnnotations())
+	}
+}
+
+type parseCartridgeRolesTestCase struct {
+	val      string
+	expected []string
+}
+
+func TestParseCartridgeRoles(t *testing.T) {
+	cases := []parseCartridgeRolesTestCase{
+		{val: `"router"`, expected: []string{"router"}},
+		{val: `["router", "storage"]`, expected: []string{"router", "storage"}},
+		{val: "router.storage", expected: []string{"router", "storage"}},
+		{val: `["router"`, expected: nil},
+		{val: "", expected: nil},
+	}
+
+	for i, c := range cases {
+		roles, err := ParseCartridgeRoles(c.val)
+		if c.expected == nil {
+			if err == nil {
+				t.Fatalf("%d: expected error, got %v", i, roles)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(roles, c.expected) {
+			t.Fatalf("%d: expected %v, got %v", i, c.expected, roles)
+		}
+	}
+}
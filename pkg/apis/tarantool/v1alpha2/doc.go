@@ -0,0 +1,4 @@
+// Package v1alpha2 contains API Schema definitions for the tarantool v1alpha2 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=tarantool.io
+package v1alpha2
@@ -0,0 +1,38 @@
+package v1alpha2
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReplicasetTemplateStatus defines the observed state of ReplicasetTemplate
+// +k8s:openapi-gen=true
+type ReplicasetTemplateStatus struct {
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ReplicasetTemplate is the Schema for the replicasettemplates API,
+// cartridge roles and vshard group are set on Role in v1alpha2
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type ReplicasetTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   *appsv1.StatefulSetSpec  `json:"spec,omitempty"`
+	Status ReplicasetTemplateStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ReplicasetTemplateList contains a list of ReplicasetTemplate
+type ReplicasetTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicasetTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReplicasetTemplate{}, &ReplicasetTemplateList{})
+}
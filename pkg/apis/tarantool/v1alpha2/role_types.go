@@ -0,0 +1,56 @@
+package v1alpha2
+
+import (
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RoleSpec defines the desired state of Role
+// +k8s:openapi-gen=true
+type RoleSpec struct {
+	// NumReplicasets is a number of StatefulSets (Tarantol replicasets) created under this Role
+	NumReplicasets *int32 `json:"numReplicasets,omitempty"`
+	// Selector is a LabelSelector to find ReplicasetTemplate resources from which StatefulSet created
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// DeleteVolumeClaims removes PersistentVolumeClaims of StatefulSets deleted on scale down
+	DeleteVolumeClaims bool `json:"deleteVolumeClaims,omitempty"`
+	// CartridgeRoles are cartridge roles enabled on replicasets of this Role
+	CartridgeRoles []string `json:"cartridgeRoles,omitempty"`
+	// VshardGroup is a vshard group replicasets of this Role belong to,
+	// empty means the default group
+	VshardGroup string `json:"vshardGroup,omitempty"`
+	// Weight is a vshard weight of replicasets of this Role, 100 by default
+	Weight *int32 `json:"weight,omitempty"`
+	// AllRW makes every instance of replicasets of this Role writable
+	AllRW bool `json:"allRW,omitempty"`
+	// Privileged runs the Tarantool container of Role pods privileged,
+	// otherwise its security context is taken from the template as is
+	Privileged bool `json:"privileged,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Role is the Schema for the roles API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.numReplicasets,statuspath=.status.numReplicasets,selectorpath=.status.selector
+type Role struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RoleSpec            `json:"spec,omitempty"`
+	Status v1alpha1.RoleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RoleList contains a list of Role
+type RoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Role `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Role{}, &RoleList{})
+}
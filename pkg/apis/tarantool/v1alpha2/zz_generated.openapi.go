@@ -0,0 +1,195 @@
+// +build !ignore_autogenerated
+
+// Code generated by openapi-gen. DO NOT EDIT.
+
+// This file was autogenerated by openapi-gen. Do not edit it manually!
+
+package v1alpha2
+
+import (
+	spec "github.com/go-openapi/spec"
+	common "k8s.io/kube-openapi/pkg/common"
+)
+
+func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+	return map[string]common.OpenAPIDefinition{
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.ReplicasetTemplate":       schema_pkg_apis_tarantool_v1alpha2_ReplicasetTemplate(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.ReplicasetTemplateStatus": schema_pkg_apis_tarantool_v1alpha2_ReplicasetTemplateStatus(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.Role":                     schema_pkg_apis_tarantool_v1alpha2_Role(ref),
+		"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.RoleSpec":                 schema_pkg_apis_tarantool_v1alpha2_RoleSpec(ref),
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha2_ReplicasetTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ReplicasetTemplate is the Schema for the replicasettemplates API, cartridge roles and vshard group are set on Role in v1alpha2",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/api/apps/v1.StatefulSetSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.ReplicasetTemplateStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.ReplicasetTemplateStatus", "k8s.io/api/apps/v1.StatefulSetSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha2_ReplicasetTemplateStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ReplicasetTemplateStatus defines the observed state of ReplicasetTemplate",
+				Properties:  map[string]spec.Schema{},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha2_Role(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Role is the Schema for the roles API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.RoleSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1.RoleStatus", "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2.RoleSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_tarantool_v1alpha2_RoleSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RoleSpec defines the desired state of Role",
+				Properties: map[string]spec.Schema{
+					"numReplicasets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NumReplicasets is a number of StatefulSets (Tarantol replicasets) created under this Role",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector is a LabelSelector to find ReplicasetTemplate resources from which StatefulSet created",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"deleteVolumeClaims": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteVolumeClaims removes PersistentVolumeClaims of StatefulSets deleted on scale down",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"cartridgeRoles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CartridgeRoles are cartridge roles enabled on replicasets of this Role",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"vshardGroup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VshardGroup is a vshard group replicasets of this Role belong to, empty means the default group",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"weight": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Weight is a vshard weight of replicasets of this Role, 100 by default",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"allRW": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AllRW makes every instance of replicasets of this Role writable",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"privileged": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Privileged runs the Tarantool container of Role pods privileged, otherwise its security context is taken from the template as is",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
@@ -0,0 +1,168 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// adminClient is the admin API client of a cluster along with the
+// configuration it was built from
+type adminClient struct {
+	config *topology.ClientConfig
+	client *http.Client
+}
+
+// newAdminClient builds admin API clients, tests swap it for a fake
+var newAdminClient = topology.NewHTTPClient
+
+// GetAdminAPIURL gets the admin API URL of the instance at host:port
+func GetAdminAPIURL(cluster *tarantoolv1alpha1.Cluster, uri string) string {
+	scheme := "http"
+	if cluster.Spec.AdminAPI != nil && cluster.Spec.AdminAPI.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/admin/api", scheme, uri)
+}
+
+// GetAdminTimeouts gets deadlines of admin calls to the cluster,
+// operations not set in spec keep topology defaults
+func GetAdminTimeouts(cluster *tarantoolv1alpha1.Cluster) topology.Timeouts {
+	timeouts := topology.DefaultTimeouts
+	if cluster.Spec.AdminAPI == nil || cluster.Spec.AdminAPI.Timeouts == nil {
+		return timeouts
+	}
+
+	spec := cluster.Spec.AdminAPI.Timeouts
+	if spec.Call != nil {
+		timeouts.Call = time.Duration(*spec.Call) * time.Second
+	}
+	if spec.Join != nil {
+		timeouts.Join = time.Duration(*spec.Join) * time.Second
+	}
+	if spec.EditTopology != nil {
+		timeouts.EditTopology = time.Duration(*spec.EditTopology) * time.Second
+	}
+	if spec.BootstrapVshard != nil {
+		timeouts.BootstrapVshard = time.Duration(*spec.BootstrapVshard) * time.Second
+	}
+
+	return timeouts
+}
+
+// GetTopologyProvider gets the backend managing the cluster topology
+func GetTopologyProvider(cluster *tarantoolv1alpha1.Cluster) string {
+	if cluster.Spec.Topology == nil || cluster.Spec.Topology.Provider == "" {
+		return topology.DefaultProvider
+	}
+
+	return cluster.Spec.Topology.Provider
+}
+
+// newTopologyService builds the backend of the cluster which manages
+// it through the instance at the admin URI
+func newTopologyService(cluster *tarantoolv1alpha1.Cluster, uri string, httpClient *http.Client) (topology.TopologyService, error) {
+	return topology.New(
+		GetTopologyProvider(cluster),
+		topology.WithTopologyEndpoint(GetAdminAPIURL(cluster, uri)),
+		topology.WithHTTPClient(httpClient),
+		topology.WithTimeouts(GetAdminTimeouts(cluster)),
+		topology.WithAdvertiseURI(func(pod *corev1.Pod) string {
+			return tarantool.GetAdvertiseURI(cluster, pod.GetName())
+		}),
+	)
+}
+
+// getAdminClient gets the client admin calls to the cluster are made with.
+// It is kept between passes, so that connections are reused, and rebuilt
+// once credentials or certificates change
+func (r *ReconcileCluster) getAdminClient(cluster *tarantoolv1alpha1.Cluster) (*http.Client, error) {
+	config, err := r.getAdminClientConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	name := types.NamespacedName{Namespace: cluster.GetNamespace(), Name: cluster.GetName()}
+
+	r.adminClientsMu.Lock()
+	defer r.adminClientsMu.Unlock()
+
+	if cached, ok := r.adminClients[name]; ok {
+		if reflect.DeepEqual(cached.config, config) {
+			return cached.client, nil
+		}
+		cached.client.CloseIdleConnections()
+	}
+
+	client, err := newAdminClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.adminClients == nil {
+		r.adminClients = make(map[types.NamespacedName]*adminClient)
+	}
+	r.adminClients[name] = &adminClient{config: config, client: client}
+
+	return client, nil
+}
+
+// forgetAdminClient drops the client of a cluster which is gone
+func (r *ReconcileCluster) forgetAdminClient(name types.NamespacedName) {
+	r.adminClientsMu.Lock()
+	defer r.adminClientsMu.Unlock()
+
+	if cached, ok := r.adminClients[name]; ok {
+		cached.client.CloseIdleConnections()
+		delete(r.adminClients, name)
+	}
+}
+
+// getAdminClientConfig reads admin API credentials and certificates of the cluster
+func (r *ReconcileCluster) getAdminClientConfig(cluster *tarantoolv1alpha1.Cluster) (*topology.ClientConfig, error) {
+	config := &topology.ClientConfig{}
+
+	spec := cluster.Spec.AdminAPI
+	if spec == nil {
+		return config, nil
+	}
+
+	if spec.Auth != nil {
+		password, err := r.getSecretValue(cluster.GetNamespace(), &spec.Auth.PasswordSecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Username = spec.Auth.Username
+		if config.Username == "" {
+			config.Username = "admin"
+		}
+		config.Password = password
+	}
+
+	if spec.TLS != nil {
+		config.InsecureSkipVerify = spec.TLS.InsecureSkipVerify
+
+		if spec.TLS.SecretName != "" {
+			secret := &corev1.Secret{}
+			if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.GetNamespace(), Name: spec.TLS.SecretName}, secret); err != nil {
+				return nil, err
+			}
+
+			config.CA = secret.Data["ca.crt"]
+			config.Cert = secret.Data[corev1.TLSCertKey]
+			config.Key = secret.Data[corev1.TLSPrivateKeyKey]
+		}
+	}
+
+	return config, nil
+}
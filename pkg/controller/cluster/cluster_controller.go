@@ -3,9 +3,11 @@ package cluster
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,8 +19,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -37,32 +41,9 @@ var space = uuid.MustParse("73692FF6-EB42-46C2-92B6-65C45191368D")
 
 var metricsGauges = make(map[string]prometheus.Gauge)
 
-// ResponseError .
-type ResponseError struct {
-	Message string `json:"message"`
-}
-
-// JoinResponseData .
-type JoinResponseData struct {
-	JoinInstance bool `json:"join_instance"`
-}
-
-// JoinResponse .
-type JoinResponse struct {
-	Errors []*ResponseError  `json:"errors,omitempty"`
-	Data   *JoinResponseData `json:"data,omitempty"`
-}
-
-// ExpelResponseData .
-type ExpelResponseData struct {
-	ExpelInstance bool `json:"expel_instance"`
-}
-
-// ExpelResponse .
-type ExpelResponse struct {
-	Errors []*ResponseError   `json:"errors,omitempty"`
-	Data   *ExpelResponseData `json:"data,omitempty"`
-}
+// HealthCheckPeriod is how often a converged Cluster is reconciled to poll
+// Cartridge for instance health, Kubernetes changes are picked up by watches
+var HealthCheckPeriod = 30 * time.Second
 
 // HasInstanceUUID .
 func HasInstanceUUID(o *corev1.Pod) bool {
@@ -87,33 +68,111 @@ func SetInstanceUUID(o *corev1.Pod) *corev1.Pod {
 	return o
 }
 
-// GetLeaderURI gets the URI to be used as the cluster leader
-func GetLeaderURI(cluster *tarantoolv1alpha1.Cluster, endpoint *corev1.Endpoints) (string, error) {
-	logger := log.WithValues("func", "GetLeaderURI", "Request.Namespace", cluster.GetNamespace())
+// isStatefulSetPod tells whether the pod name is the name StatefulSet gives to its pods
+func isStatefulSetPod(podName string, stsName string) bool {
+	if !strings.HasPrefix(podName, fmt.Sprintf("%s-", stsName)) {
+		return false
+	}
+
+	_, err := strconv.Atoi(strings.TrimPrefix(podName, fmt.Sprintf("%s-", stsName)))
+	return err == nil
+}
 
-	target := endpoint.Subsets[0].Addresses[0].TargetRef
-	ret := fmt.Sprintf("%s.%s.%s.svc.cluster.local:8081", target.Name, cluster.GetName(), cluster.GetNamespace())
+// GetServicePorts lists ports of the cluster headless Service
+func GetServicePorts(cluster *tarantoolv1alpha1.Cluster) []corev1.ServicePort {
+	port := tarantool.GetBinaryPort(cluster)
+
+	return []corev1.ServicePort{
+		{
+			Name:       "app",
+			Port:       port,
+			TargetPort: intstr.FromInt(int(port)),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+}
 
-	logger.Info("Setting leader URI", "URI", ret)
+// isExpelRequired tells whether a deleted pod is gone for good: its StatefulSet
+// was scaled down below the pod ordinal or is being deleted itself
+func (r *ReconcileCluster) isExpelRequired(pod *corev1.Pod) (bool, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return true, nil
+	}
 
-	_, err := http.Get(fmt.Sprintf("http://%s", ret))
+	sts := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: pod.GetNamespace(), Name: owner.Name}, sts); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if sts.GetDeletionTimestamp() != nil {
+		return true, nil
+	}
+
+	ordinal, err := strconv.Atoi(strings.TrimPrefix(pod.GetName(), fmt.Sprintf("%s-", sts.GetName())))
 	if err != nil {
-		logger.Error(err, "failed testing endpoint connectivity")
-		return "", err
+		return false, err
+	}
+
+	return sts.Spec.Replicas != nil && int32(ordinal) >= *sts.Spec.Replicas, nil
+}
+
+// releasePods removes instance finalizers from all pods of the cluster
+// without expelling them
+func (r *ReconcileCluster) releasePods(namespace string, clusterID string) error {
+	podList := &corev1.PodList{}
+	selector := labels.SelectorFromSet(labels.Set{"tarantool.io/cluster-id": clusterID})
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: namespace, LabelSelector: selector}, podList); err != nil {
+		return err
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !tarantool.HasFinalizer(pod) {
+			continue
+		}
+
+		tarantool.RemoveFinalizer(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			return err
+		}
 	}
 
-	return ret, nil
+	return nil
 }
 
 // Add creates a new Cluster Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	r := newReconciler(mgr)
+
+	// admin calls in flight are cancelled once the manager stops
+	err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		<-stop
+		r.cancel()
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileCluster{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+func newReconciler(mgr manager.Manager) *ReconcileCluster {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &ReconcileCluster{
+		client:       mgr.GetClient(),
+		scheme:       mgr.GetScheme(),
+		ctx:          ctx,
+		cancel:       cancel,
+		adminClients: make(map[types.NamespacedName]*adminClient),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -130,20 +189,44 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// TODO(user): Modify this to be the types you create that are owned by the primary resource
-	// Watch for changes to secondary resource Pods and requeue the owner Cluster
-	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+	// pods and StatefulSets of the cluster carry its name in the label
+	byClusterID := &handler.EnqueueRequestsFromMapFunc{
 		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
-			if a.Meta.GetLabels() == nil {
-				return []reconcile.Request{}
+			clusterID, ok := a.Meta.GetLabels()["tarantool.io/cluster-id"]
+			if !ok {
+				return nil
 			}
 			return []reconcile.Request{
 				{NamespacedName: types.NamespacedName{
 					Namespace: a.Meta.GetNamespace(),
-					Name:      a.Meta.GetLabels()["tarantool.io/cluster-id"],
+					Name:      clusterID,
 				}},
 			}
 		}),
+	}
+
+	// Watch for changes to secondary resource Pods and requeue the owner Cluster
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, byClusterID)
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}}, byClusterID)
+	if err != nil {
+		return err
+	}
+
+	// cluster Service is headless, its Endpoints are named after the Cluster
+	// and list instances the leader is elected from
+	mgrClient := mgr.GetClient()
+	err = c.Watch(&source.Kind{Type: &corev1.Endpoints{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			name := types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()}
+			if err := mgrClient.Get(context.TODO(), name, &tarantoolv1alpha1.Cluster{}); err != nil {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: name}}
+		}),
 	})
 	if err != nil {
 		return err
@@ -161,6 +244,13 @@ type ReconcileCluster struct {
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
 	scheme *runtime.Scheme
+
+	// ctx bounds admin calls, it is cancelled when the manager stops
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	adminClientsMu sync.Mutex
+	adminClients   map[types.NamespacedName]*adminClient
 }
 
 // Reconcile reads that state of the cluster for a Cluster object and makes changes based on the state read
@@ -178,24 +268,51 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 	cluster := &tarantoolv1alpha1.Cluster{}
 	if err := r.client.Get(context.TODO(), request.NamespacedName, cluster); err != nil {
 		if errors.IsNotFound(err) {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+			r.forgetAdminClient(request.NamespacedName)
+
+			// there is nobody left to expel instances from, let pods go
+			if err := r.releasePods(request.Namespace, request.Name); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{}, nil
 		}
 
-		return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+		return reconcile.Result{}, err
 	}
 
+	if cluster.GetDeletionTimestamp() != nil {
+		reqLogger.Info("Cluster is being deleted, releasing pods")
+		if err := r.releasePods(request.Namespace, request.Name); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// status is written back on every pass, however it ends
+	status := cluster.Status.DeepCopy()
+	defer func() {
+		if reflect.DeepEqual(cluster.Status, *status) {
+			return
+		}
+
+		cluster.Status = *status
+		if err := r.client.Status().Update(context.TODO(), cluster); err != nil {
+			reqLogger.Error(err, "failed to update cluster status")
+		}
+	}()
+
 	clusterSelector, err := metav1.LabelSelectorAsSelector(cluster.Spec.Selector)
 	if err != nil {
-		return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+		return reconcile.Result{}, err
 	}
 
 	roleList := &tarantoolv1alpha1.RoleList{}
 	if err := r.client.List(context.TODO(), &client.ListOptions{LabelSelector: clusterSelector}, roleList); err != nil {
 		if errors.IsNotFound(err) {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+			return reconcile.Result{}, nil
 		}
 
-		return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+		return reconcile.Result{}, err
 	}
 
 	for _, role := range roleList.Items {
@@ -210,10 +327,10 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 		annotations["tarantool.io/cluster-id"] = cluster.GetName()
 		role.SetAnnotations(annotations)
 		if err := controllerutil.SetControllerReference(cluster, &role, r.scheme); err != nil {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+			return reconcile.Result{}, err
 		}
 		if err := r.client.Update(context.TODO(), &role); err != nil {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+			return reconcile.Result{}, err
 		}
 
 		reqLogger.Info("Set role ownership", "Role.Name", role.GetName(), "Cluster.Name", cluster.GetName())
@@ -221,106 +338,187 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 
 	reqLogger.Info("Roles reconciled, moving to pod reconcile")
 
-	// ensure cluster wide Service exists
+	// ensure cluster wide Service exists and exposes the binary port
 	svc := &corev1.Service{}
 	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.GetNamespace(), Name: cluster.GetName()}, svc); err != nil {
-		if errors.IsNotFound(err) {
-			svc.Name = cluster.GetName()
-			svc.Namespace = cluster.GetNamespace()
-			svc.Spec = corev1.ServiceSpec{
-				Selector:  cluster.Spec.Selector.MatchLabels,
-				ClusterIP: "None",
-				Ports: []corev1.ServicePort{
-					{
-						Name:     "app",
-						Port:     3301,
-						Protocol: "TCP",
-					},
-				},
-			}
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
 
-			if err := controllerutil.SetControllerReference(cluster, svc, r.scheme); err != nil {
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-			}
+		svc.Name = cluster.GetName()
+		svc.Namespace = cluster.GetNamespace()
+		svc.Spec = corev1.ServiceSpec{
+			Selector:  cluster.Spec.Selector.MatchLabels,
+			ClusterIP: "None",
+			Ports:     GetServicePorts(cluster),
+		}
 
-			if err := r.client.Create(context.TODO(), svc); err != nil {
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-			}
+		if err := controllerutil.SetControllerReference(cluster, svc, r.scheme); err != nil {
+			return reconcile.Result{}, err
 		}
-	}
 
-	// ensure Cluster leader elected
-	ep := &corev1.Endpoints{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.GetNamespace(), Name: cluster.GetName()}, ep); err != nil {
-		return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+		if err := r.client.Create(context.TODO(), svc); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if ports := GetServicePorts(cluster); !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		reqLogger.Info("Updating cluster Service ports", "Service.Name", svc.GetName())
+		svc.Spec.Ports = ports
+		if err := r.client.Update(context.TODO(), svc); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
-	if len(ep.Subsets) == 0 || len(ep.Subsets[0].Addresses) == 0 {
-		reqLogger.Info("No available Endpoint resource configured for Cluster, waiting")
-		return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+
+	// pods which are restarted keep their place in the topology, so only
+	// pods deleted for good are held until they are expelled from cartridge
+	podList := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: request.Namespace, LabelSelector: clusterSelector}, podList); err != nil {
+		return reconcile.Result{}, err
 	}
 
-	leader, ok := ep.Annotations["tarantool.io/leader"]
-	if !ok {
-		if leader == "" {
-			reqLogger.Info("leader is not elected")
-			// return reconcile.Result{RequeueAfter: time.Duration(5000 * time.Millisecond)}, nil
+	podsToExpel := []*corev1.Pod{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.GetDeletionTimestamp() == nil || !tarantool.HasFinalizer(pod) {
+			continue
 		}
 
-		leader, err := GetLeaderURI(cluster, ep)
+		expelRequired, err := r.isExpelRequired(pod)
 		if err != nil {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+			return reconcile.Result{}, err
 		}
 
-		if ep.Annotations == nil {
-			ep.Annotations = make(map[string]string)
+		if expelRequired {
+			podsToExpel = append(podsToExpel, pod)
+			continue
 		}
 
-		ep.Annotations["tarantool.io/leader"] = leader
-		if err := r.client.Update(context.TODO(), ep); err != nil {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+		reqLogger.Info("Pod is restarting, removing finalizer", "Pod.Name", pod.GetName())
+		tarantool.RemoveFinalizer(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
 
 	stsList := &appsv1.StatefulSetList{}
 	if err := r.client.List(context.TODO(), &client.ListOptions{LabelSelector: clusterSelector}, stsList); err != nil {
 		if errors.IsNotFound(err) {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+			return reconcile.Result{}, nil
 		}
 
-		return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+		return reconcile.Result{}, err
 	}
 
-	topologyClient := topology.NewBuiltInTopologyService(topology.WithTopologyEndpoint(fmt.Sprintf("http://%s/admin/api", leader)), topology.WithClusterID(cluster.GetName()))
+	joined, expected := CountJoinedInstances(stsList, podList)
+	if joined == expected {
+		status.SetCondition(tarantoolv1alpha1.ClusterAllInstancesJoined, corev1.ConditionTrue, "Joined", fmt.Sprintf("%d instances joined", joined))
+	} else {
+		status.SetCondition(tarantoolv1alpha1.ClusterAllInstancesJoined, corev1.ConditionFalse, "Joining", fmt.Sprintf("%d of %d instances joined", joined, expected))
+	}
+
+	bootstrapped := false
 	for _, sts := range stsList.Items {
-		for i := 0; i < int(*sts.Spec.Replicas); i++ {
-			pod := &corev1.Pod{}
-			name := types.NamespacedName{
-				Namespace: request.Namespace,
-				Name:      fmt.Sprintf("%s-%d", sts.GetName(), i),
-			}
-			if err := r.client.Get(context.TODO(), name, pod); err != nil {
-				if errors.IsNotFound(err) {
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
-				}
+		if sts.GetAnnotations()["tarantool.io/isBootstrapped"] == "1" {
+			bootstrapped = true
+		}
+	}
+	if bootstrapped {
+		status.SetCondition(tarantoolv1alpha1.ClusterBootstrapped, corev1.ConditionTrue, "Bootstrapped", "vshard is bootstrapped")
+	} else {
+		status.SetCondition(tarantoolv1alpha1.ClusterBootstrapped, corev1.ConditionFalse, "NotBootstrapped", "vshard is not bootstrapped yet")
+	}
 
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-			}
+	// replicasets being removed can not serve as the cluster leader
+	removedStatefulSets := []string{}
+	for _, sts := range stsList.Items {
+		if sts.GetAnnotations()["tarantool.io/removalRequested"] == "1" {
+			removedStatefulSets = append(removedStatefulSets, sts.GetName())
+		}
+	}
 
-			podLogger := reqLogger.WithValues("Pod.Name", pod.GetName())
-			if HasInstanceUUID(pod) {
-				continue
-			}
-			podLogger.Info("starting: set instance uuid")
-			pod = SetInstanceUUID(pod)
+	// ensure Cluster leader elected
+	ep := &corev1.Endpoints{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.GetNamespace(), Name: cluster.GetName()}, ep); err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(ep.Subsets) == 0 || len(ep.Subsets[0].Addresses) == 0 {
+		reqLogger.Info("No available Endpoint resource configured for Cluster, waiting")
+		return reconcile.Result{}, nil
+	}
+
+	httpClient, err := r.getAdminClient(cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// admin calls are abandoned once the manager stops
+	ctx := r.ctx
+	leader, err := GetLeaderURI(ctx, cluster, httpClient, ep, removedStatefulSets, status.Leader)
+	if err != nil {
+		status.Leader = ""
+		return reconcile.Result{}, err
+	}
+	status.Leader = leader
 
+	topologyClient, err := newTopologyService(cluster, leader, httpClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// the leader is re-elected on the next pass once it fails an admin call
+	defer func() {
+		if err := topologyClient.Failed(); err != nil {
+			reqLogger.Info("leader failed an admin call, re-elect leader", "URI", leader, "error", err.Error())
+			status.Leader = ""
+		}
+	}()
+
+	for _, pod := range podsToExpel {
+		podLogger := reqLogger.WithValues("Pod.Name", pod.GetName())
+
+		if strings.HasPrefix(leader, fmt.Sprintf("%s.", pod.GetName())) {
+			podLogger.Info("pod to expel is the current leader, re-elect leader")
+			status.Leader = ""
+			return reconcile.Result{Requeue: true}, nil
+		}
+
+		if !tarantool.IsExpelling(pod) {
+			tarantool.MarkExpelling(pod)
 			if err := r.client.Update(context.TODO(), pod); err != nil {
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+				return reconcile.Result{}, err
+			}
+			podLogger.Info("marked as expelling")
+		}
+
+		if err := topologyClient.Expel(ctx, pod); err != nil {
+			if !topology.IsAlreadyExpelled(err) {
+				podLogger.Error(err, "Expel error")
+				return adminCallResult(err)
 			}
+			podLogger.Info("Already expelled")
+		}
+
+		tarantool.RemoveFinalizer(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			return reconcile.Result{}, err
+		}
+		podLogger.Info("expelled from the cluster")
+	}
 
-			podLogger.Info("success: set instance uuid", "UUID", pod.GetLabels()["tarantool.io/instance-uuid"])
+	for _, sts := range stsList.Items {
+		stsAnnotations := sts.GetAnnotations()
+		if stsAnnotations["tarantool.io/removalRequested"] != "1" || stsAnnotations["tarantool.io/scheduledDelete"] != "1" || stsAnnotations["tarantool.io/isExpelled"] == "1" {
+			continue
+		}
+
+		stsLogger := reqLogger.WithValues("StatefulSet.Name", sts.GetName())
+
+		if isStatefulSetPod(strings.Split(leader, ".")[0], sts.GetName()) {
+			stsLogger.Info("replicaset to expel holds the current leader, re-elect leader")
+			status.Leader = ""
 			return reconcile.Result{Requeue: true}, nil
 		}
 
+		pods := []*corev1.Pod{}
 		for i := 0; i < int(*sts.Spec.Replicas); i++ {
 			pod := &corev1.Pod{}
 			name := types.NamespacedName{
@@ -329,137 +527,106 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 			}
 			if err := r.client.Get(context.TODO(), name, pod); err != nil {
 				if errors.IsNotFound(err) {
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+					return reconcile.Result{}, nil
 				}
 
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-			}
-
-			if tarantool.IsJoined(pod) {
-				continue
+				return reconcile.Result{}, err
 			}
+			pods = append(pods, pod)
+		}
 
-			if err := topologyClient.Join(pod); err != nil {
-				if topology.IsAlreadyJoined(err) {
-					tarantool.MarkJoined(pod)
-					if err := r.client.Update(context.TODO(), pod); err != nil {
-						return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-					}
-					reqLogger.Info("Already joined", "Pod.Name", pod.Name)
-					continue
-				}
+		replicaSetList, err := topologyClient.GetReplicaSetList(ctx)
+		if err != nil {
+			stsLogger.Error(err, "failed to get replicaset list")
+			return adminCallResult(err)
+		}
 
-				if topology.IsTopologyDown(err) {
-					reqLogger.Info("Topology is down", "Pod.Name", pod.Name)
-					continue
+		for _, pod := range expelOrder(pods, getReplicaset(&replicaSetList.Data, sts.GetLabels()["tarantool.io/replicaset-uuid"])) {
+			if !tarantool.IsExpelling(pod) {
+				tarantool.MarkExpelling(pod)
+				if err := r.client.Update(context.TODO(), pod); err != nil {
+					return reconcile.Result{}, err
 				}
+			}
 
-				reqLogger.Error(err, "Join error")
-
-				if strings.Contains(err.Error(), "no route to host") || strings.Contains(err.Error(), "Timeout exceeded while awaiting headers") {
-					reqLogger.Info("no route to leader, IP of the pod could have changed, re-elect leader")
-					delete(ep.Annotations, "tarantool.io/leader")
-
-					if err := r.client.Update(context.TODO(), ep); err != nil {
-						return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-					}
-				}
+			if err := topologyClient.Expel(ctx, pod); err != nil && !topology.IsAlreadyExpelled(err) {
+				stsLogger.Error(err, "Expel error", "Pod.Name", pod.GetName())
+				return adminCallResult(err)
+			}
+		}
 
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+		stsAnnotations["tarantool.io/isExpelled"] = "1"
+		sts.SetAnnotations(stsAnnotations)
+		if err := r.client.Update(context.TODO(), &sts); err != nil {
+			return reconcile.Result{}, err
+		}
+		stsLogger.Info("all replicaset instances are expelled")
+	}
 
-			} else {
-				tarantool.MarkJoined(pod)
-				if err := r.client.Update(context.TODO(), pod); err != nil {
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-				}
-			}
+	// replicaset roles are compared along with their dependencies
+	knownRoles, err := topologyClient.GetKnownRoles(ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get known roles")
+		return adminCallResult(err)
+	}
 
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+	allJoined, err := r.reconcileTopology(ctx, cluster, stsList, roleList, knownRoles, topologyClient)
+	if err != nil {
+		if topology.IsPermanent(err) {
+			reqLogger.Error(err, "topology changes are rejected")
+			status.SetCondition(tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionFalse, "Rejected", err.Error())
 		}
+		return adminCallResult(err)
+	}
+	if !allJoined {
+		reqLogger.Info("Not all instances are ready to join, waiting")
+		return reconcile.Result{}, nil
 	}
 
-	data, err := topologyClient.GetServerStat()
+	data, err := topologyClient.GetServerStat(ctx)
 	for _, sts := range stsList.Items {
 		stsAnnotations := sts.GetAnnotations()
 		weight, _ := stsAnnotations["tarantool.io/replicaset-weight"]
 
-		if weight == "0" {
+		if weight == "0" && stsAnnotations["tarantool.io/removalRequested"] == "1" && stsAnnotations["tarantool.io/scheduledDelete"] != "1" {
 			reqLogger.Info("weight is set to 0, checking replicaset buckets for scheduled deletion")
 
 			if err != nil {
 				reqLogger.Error(err, "failed to get server stats")
 			} else {
+				found := false
+				bucketsCount := 0
 				for i := 0; i < len(data.Stats); i++ {
-					if strings.HasPrefix(data.Stats[i].URI, sts.GetName()) {
-						reqLogger.Info("Found statefulset to check for buckets count", "sts.Name", sts.GetName())
-
-						bucketsCount := data.Stats[i].Statistics.BucketsCount
-						if bucketsCount == 0 {
-							reqLogger.Info("replicaset has migrated all of its buckets away, schedule to remove", "sts.Name", sts.GetName())
-
-							stsAnnotations["tarantool.io/scheduledDelete"] = "1"
-							sts.SetAnnotations(stsAnnotations)
-							if err := r.client.Update(context.TODO(), &sts); err != nil {
-								reqLogger.Error(err, "failed to set scheduled deletion annotation")
-							}
-						} else {
-							reqLogger.Info("replicaset still has buckets, retry checking on next run", "sts.Name", sts.GetName(), "buckets", bucketsCount)
-						}
+					if isStatefulSetPod(strings.Split(data.Stats[i].URI, ".")[0], sts.GetName()) {
+						found = true
+						bucketsCount += data.Stats[i].Statistics.BucketsCount
 					}
 				}
-			}
-		}
 
-		for i := 0; i < int(*sts.Spec.Replicas); i++ {
-			pod := &corev1.Pod{}
-			name := types.NamespacedName{
-				Namespace: request.Namespace,
-				Name:      fmt.Sprintf("%s-%d", sts.GetName(), i),
-			}
-
-			if err := r.client.Get(context.TODO(), name, pod); err != nil {
-				if errors.IsNotFound(err) {
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
-				}
-
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-			}
-
-			if tarantool.IsJoined(pod) == false {
-				reqLogger.Info("Not all instances joined, skip weight change", "StatefulSet.Name", sts.GetName())
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
-			}
-		}
-
-		replicaSetList, err := topologyClient.GetReplicaSetList()
-		if err != nil {
-			return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-		}
-
-		for i := 0; i < len(replicaSetList.Data.ReplicaSets); i++ {
-			if replicaSetList.Data.ReplicaSets[i].UUID == stsAnnotations["tarantool.io/replicaset-uuid"] {
-				reqLogger.Info("found", "sts.Name", sts.GetName())
-
-				intWeight, err := strconv.Atoi(weight)
-				if err != nil {
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-				}
+				if found && bucketsCount == 0 {
+					reqLogger.Info("replicaset has migrated all of its buckets away, schedule to remove", "sts.Name", sts.GetName())
 
-				if intWeight != replicaSetList.Data.ReplicaSets[i].Weight {
-					reqLogger.Info("weight changed, run update", "newWeight", intWeight, "oldWeight", replicaSetList.Data.ReplicaSets[i].Weight)
-					if err := topologyClient.SetWeight(sts.GetLabels()["tarantool.io/replicaset-uuid"], weight); err != nil {
-						return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
+					stsAnnotations["tarantool.io/scheduledDelete"] = "1"
+					sts.SetAnnotations(stsAnnotations)
+					if err := r.client.Update(context.TODO(), &sts); err != nil {
+						reqLogger.Error(err, "failed to set scheduled deletion annotation")
 					}
+				} else if found {
+					reqLogger.Info("replicaset still has buckets, retry checking on next run", "sts.Name", sts.GetName(), "buckets", bucketsCount)
 				}
 			}
 		}
+	}
 
-		if stsAnnotations == nil {
-			stsAnnotations = make(map[string]string)
-		}
+	replicaSetList, listErr := topologyClient.GetReplicaSetList(ctx)
+	if listErr != nil {
+		reqLogger.Error(listErr, "failed to get replicaset list")
+		status.SetCondition(tarantoolv1alpha1.ClusterHealthy, corev1.ConditionUnknown, "TopologyUnavailable", listErr.Error())
+	} else {
+		SetTopologyStatus(status, &replicaSetList.Data)
+		SetTopologyDrift(status, stsList, roleList, &replicaSetList.Data, knownRoles)
 	}
 
-	replicaSetList, err := topologyClient.GetReplicaSetList()
 	for i := 0; i < len(replicaSetList.Data.Servers); i++ {
 		reqLogger.Info("server", "alias", replicaSetList.Data.Servers[i].Alias, "status", replicaSetList.Data.Servers[i].Status)
 
@@ -493,94 +660,184 @@ func (r *ReconcileCluster) Reconcile(request reconcile.Request) (reconcile.Resul
 		stsAnnotations := sts.GetAnnotations()
 		if stsAnnotations["tarantool.io/isBootstrapped"] != "1" {
 			reqLogger.Info("cluster is not bootstrapped, bootstrapping", "Statefulset.Name", sts.GetName())
-			if err := topologyClient.BootstrapVshard(); err != nil {
-				if topology.IsAlreadyBootstrapped(err) {
-					stsAnnotations["tarantool.io/isBootstrapped"] = "1"
-					sts.SetAnnotations(stsAnnotations)
+			if err := topologyClient.BootstrapVshard(ctx); err != nil && !topology.IsAlreadyBootstrapped(err) {
+				reqLogger.Error(err, "Bootstrap vshard error")
+				status.SetCondition(tarantoolv1alpha1.ClusterBootstrapped, corev1.ConditionFalse, "BootstrapFailed", err.Error())
+				return adminCallResult(err)
+			}
 
-					if err := r.client.Update(context.TODO(), &sts); err != nil {
-						reqLogger.Error(err, "failed to set bootstrapped annotation")
-					}
+			stsAnnotations["tarantool.io/isBootstrapped"] = "1"
+			sts.SetAnnotations(stsAnnotations)
 
-					reqLogger.Info("Added bootstrapped annotation", "StatefulSet.Name", sts.GetName())
+			if err := r.client.Update(context.TODO(), &sts); err != nil {
+				reqLogger.Error(err, "failed to set bootstrapped annotation")
+			}
 
-					cluster.Status.State = "Ready"
-					r.client.Status().Update(context.TODO(), cluster)
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
-				}
+			reqLogger.Info("Added bootstrapped annotation", "StatefulSet.Name", sts.GetName())
 
-				reqLogger.Error(err, "Bootstrap vshard error")
-				return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-			}
+			status.State = "Ready"
+			status.SetCondition(tarantoolv1alpha1.ClusterBootstrapped, corev1.ConditionTrue, "Bootstrapped", "vshard is bootstrapped")
 		} else {
 			reqLogger.Info("cluster is already bootstrapped, not retrying", "Statefulset.Name", sts.GetName())
 		}
+	}
 
-		if stsAnnotations["tarantool.io/failoverEnabled"] == "1" {
-			reqLogger.Info("failover is enabled, not retrying")
-		} else {
-			var hasBeenEnabled = false
-			var failoverMode = stsAnnotations["tarantool.io/failoverMode"]
-			reqLogger.Info("found failover mode", "mode", failoverMode)
-			if failoverMode == "eventual" {
-				reqLogger.Info("configuring eventual failover")
-				if err := topologyClient.SetEventualFailover(true); err != nil {
-					reqLogger.Error(err, "failed to enable cluster failover")
-				} else {
-					reqLogger.Info("enabled failover")
-					hasBeenEnabled = true
-				}
-			} else if failoverMode == "stateful-tarantool" {
-				reqLogger.Info("configuring stateful failover with tarantool backend")
+	if err := r.reconcileFailover(ctx, cluster, roleList, topologyClient, status); err != nil {
+		reqLogger.Error(err, "failed to configure failover")
+	}
 
-				configmap := &corev1.ConfigMap{}
-				name := types.NamespacedName{
-					Namespace: request.Namespace,
-					Name:      "cluster-config",
-				}
+	if listErr == nil {
+		if err := r.reconcileRollout(ctx, cluster, stsList, &replicaSetList.Data, ep, removedStatefulSets, topologyClient, status); err != nil {
+			reqLogger.Error(err, "failed to roll out replicasets")
+		}
+	}
 
-				if err := r.client.Get(context.TODO(), name, configmap); err != nil {
-					if errors.IsNotFound(err) {
-						return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
-					}
+	if err := r.reconcileMemtx(ctx, cluster, stsList, topologyClient); err != nil {
+		reqLogger.Error(err, "failed to grow memtx_memory")
+	}
 
-					return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, err
-				}
+	status.ObservedGeneration = cluster.GetGeneration()
 
-				// Defaults for config
-				var stateboardURI = "stateboard:3301"
-				var stateboardPassword = "password"
+	// everything above is driven by watches, Cartridge is the only
+	// source of changes the operator has to poll for
+	return reconcile.Result{RequeueAfter: HealthCheckPeriod}, nil
+}
 
-				if val, ok := configmap.Data["stateboardUri"]; ok {
-					stateboardURI = val
-				}
-				if val, ok := configmap.Data["stateboardPassword"]; ok {
-					stateboardPassword = val
-				}
+// expelOrder orders replicaset instances to be expelled: replicas go first
+// and the active master last, cartridge does not expel a replicaset leader
+// while there are other instances in the replicaset. The highest ordinal goes
+// first when the replicaset is not known to cartridge
+func expelOrder(pods []*corev1.Pod, rs *topology.ReplicaSet) []*corev1.Pod {
+	master := ""
+	if rs != nil && rs.ActiveMaster != nil {
+		master = rs.ActiveMaster.UUID
+	}
 
-				if err := topologyClient.SetTarantoolStatefulFailover(true, stateboardURI, stateboardPassword); err != nil {
-					reqLogger.Error(err, "failed to enable stateful tarantool failover")
-				} else {
-					hasBeenEnabled = true
-				}
+	ordered := make([]*corev1.Pod, 0, len(pods))
+	var last *corev1.Pod
+	for i := len(pods) - 1; i >= 0; i-- {
+		if master != "" && pods[i].GetLabels()["tarantool.io/instance-uuid"] == master {
+			last = pods[i]
+			continue
+		}
+		ordered = append(ordered, pods[i])
+	}
+	if last != nil {
+		ordered = append(ordered, last)
+	}
 
-			} else if failoverMode == "stateful-consul" {
-				reqLogger.Info("configuring stateful failover with consul backend")
-				// blyat: Implement this when available in cartridge
+	return ordered
+}
 
-			} else {
-				reqLogger.Info("could not determine which failover mode to enable")
-			}
+// getReplicaset finds the replicaset in cartridge topology by uuid
+func getReplicaset(data *topology.ReplicaSetData, uuid string) *topology.ReplicaSet {
+	for _, rs := range data.ReplicaSets {
+		if rs.UUID == uuid {
+			return rs
+		}
+	}
 
-			if hasBeenEnabled {
-				stsAnnotations["tarantool.io/failoverEnabled"] = "1"
-				sts.SetAnnotations(stsAnnotations)
-				if err := r.client.Update(context.TODO(), &sts); err != nil {
-					reqLogger.Error(err, "failed to set failover enabled annotation")
-				}
-			}
+	return nil
+}
+
+// adminCallResult ends a pass failed by an admin call: transient failures are
+// retried with backoff, calls cartridge rejected are not made again until
+// the cluster changes or the next health check
+func adminCallResult(err error) (reconcile.Result, error) {
+	if topology.IsPermanent(err) {
+		return reconcile.Result{RequeueAfter: HealthCheckPeriod}, nil
+	}
+
+	return reconcile.Result{}, err
+}
+
+// CountJoinedInstances counts joined pods of replicasets which are not being
+// expelled against the number of pods expected in them
+func CountJoinedInstances(stsList *appsv1.StatefulSetList, podList *corev1.PodList) (int, int) {
+	replicas := make(map[string]int)
+	expected := 0
+	for _, sts := range stsList.Items {
+		stsAnnotations := sts.GetAnnotations()
+		if stsAnnotations["tarantool.io/removalRequested"] == "1" && stsAnnotations["tarantool.io/scheduledDelete"] == "1" {
+			continue
+		}
+		if sts.Spec.Replicas == nil {
+			continue
+		}
+
+		replicas[sts.GetName()] = int(*sts.Spec.Replicas)
+		expected += int(*sts.Spec.Replicas)
+	}
+
+	joined := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil || pod.GetDeletionTimestamp() != nil || !tarantool.IsJoined(pod) {
+			continue
+		}
+
+		count, ok := replicas[owner.Name]
+		if !ok {
+			continue
+		}
+
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(pod.GetName(), fmt.Sprintf("%s-", owner.Name)))
+		if err == nil && ordinal < count {
+			joined++
+		}
+	}
+
+	return joined, expected
+}
+
+// SetTopologyStatus copies cartridge topology into Cluster status
+// and sets the Healthy condition
+func SetTopologyStatus(status *tarantoolv1alpha1.ClusterStatus, data *topology.ReplicaSetData) {
+	status.Replicasets = []tarantoolv1alpha1.ClusterReplicasetStatus{}
+	for _, rs := range data.ReplicaSets {
+		status.Replicasets = append(status.Replicasets, tarantoolv1alpha1.ClusterReplicasetStatus{
+			UUID:        rs.UUID,
+			Alias:       rs.Alias,
+			Status:      rs.Status,
+			Roles:       rs.Roles,
+			Weight:      rs.Weight,
+			AllRW:       rs.AllRW,
+			VshardGroup: rs.VshardGroup,
+		})
+	}
+
+	unhealthy := []string{}
+	status.Servers = []tarantoolv1alpha1.ClusterServerStatus{}
+	for _, server := range data.Servers {
+		serverStatus := tarantoolv1alpha1.ClusterServerStatus{
+			UUID:    server.UUID,
+			Alias:   server.Alias,
+			URI:     server.URI,
+			Status:  server.Status,
+			Message: server.Message,
+		}
+		if server.Replicaset != nil {
+			serverStatus.ReplicasetUUID = server.Replicaset.UUID
+		}
+		status.Servers = append(status.Servers, serverStatus)
+
+		if server.Status != "healthy" {
+			unhealthy = append(unhealthy, server.URI)
 		}
 	}
 
-	return reconcile.Result{RequeueAfter: time.Duration(5 * time.Second)}, nil
+	sort.Slice(status.Replicasets, func(i, j int) bool {
+		return status.Replicasets[i].UUID < status.Replicasets[j].UUID
+	})
+	sort.Slice(status.Servers, func(i, j int) bool {
+		return status.Servers[i].URI < status.Servers[j].URI
+	})
+
+	if len(unhealthy) == 0 {
+		status.SetCondition(tarantoolv1alpha1.ClusterHealthy, corev1.ConditionTrue, "Healthy", fmt.Sprintf("%d servers are healthy", len(data.Servers)))
+	} else {
+		sort.Strings(unhealthy)
+		status.SetCondition(tarantoolv1alpha1.ClusterHealthy, corev1.ConditionFalse, "Unhealthy", fmt.Sprintf("unhealthy servers: %s", strings.Join(unhealthy, ", ")))
+	}
 }
@@ -0,0 +1,674 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	"github.com/tarantool/tarantool-operator/pkg/topology/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var kvRequest = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "kv"}}
+
+// newKVCluster makes objects of the kv cluster: a storage Role
+// with a single StatefulSet of ready pods and the cluster Endpoints
+func newKVCluster(replicas int32) []runtime.Object {
+	clusterLabels := map[string]string{"tarantool.io/cluster-id": "kv"}
+
+	cluster := &tarantoolv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kv", UID: "cluster-uid"},
+		Spec: tarantoolv1alpha1.ClusterSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: clusterLabels},
+			Failover: &tarantoolv1alpha1.FailoverSpec{Mode: "eventual"},
+		},
+	}
+
+	role := &tarantoolv1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "storage", UID: "role-uid", Labels: clusterLabels},
+	}
+
+	ep := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kv"},
+		Subsets:    []corev1.EndpointSubset{{}},
+	}
+
+	objs := append([]runtime.Object{cluster, role}, newKVReplicaset(role, ep, "storage-0", "rs-uuid", replicas)...)
+
+	return append(objs, ep)
+}
+
+// newKVReplicaset makes a StatefulSet of the storage Role with ready pods
+// and lists them in the cluster Endpoints
+func newKVReplicaset(role *tarantoolv1alpha1.Role, ep *corev1.Endpoints, name string, uuid string, replicas int32) []runtime.Object {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels: map[string]string{
+				"tarantool.io/cluster-id":      "kv",
+				"tarantool.io/replicaset-uuid": uuid,
+			},
+			Annotations: map[string]string{
+				tarantoolv1alpha1.RolesToAssignAnnotation:    `["vshard-storage"]`,
+				tarantoolv1alpha1.ReplicasetWeightAnnotation: "1",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(role, tarantoolv1alpha1.SchemeGroupVersion.WithKind("Role")),
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"tarantool.io/useVshardGroups": "0"},
+				},
+			},
+		},
+	}
+
+	objs := []runtime.Object{sts}
+	for i := 0; i < int(replicas); i++ {
+		podName := fmt.Sprintf("%s-%d", name, i)
+		objs = append(objs, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      podName,
+				Labels:    map[string]string{"tarantool.io/cluster-id": "kv"},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(sts, appsv1.SchemeGroupVersion.WithKind("StatefulSet")),
+				},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		})
+		ep.Subsets[0].Addresses = append(ep.Subsets[0].Addresses, corev1.EndpointAddress{
+			TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: podName},
+		})
+	}
+
+	return objs
+}
+
+// newTestReconciler makes a reconciler talking to the fake cartridge,
+// the returned func restores the admin client
+func newTestReconciler(cartridge *fake.Cartridge, objs ...runtime.Object) (*ReconcileCluster, func()) {
+	newClient := newAdminClient
+	newAdminClient = func(config *topology.ClientConfig) (*http.Client, error) {
+		return cartridge.Client(), nil
+	}
+
+	return &ReconcileCluster{
+		client:       fake.NewClient(objs...),
+		scheme:       scheme.Scheme,
+		ctx:          context.Background(),
+		adminClients: make(map[types.NamespacedName]*adminClient),
+	}, func() { newAdminClient = newClient }
+}
+
+func getKVCluster(t *testing.T, c client.Client) *tarantoolv1alpha1.Cluster {
+	cluster := &tarantoolv1alpha1.Cluster{}
+	if err := c.Get(context.TODO(), kvRequest.NamespacedName, cluster); err != nil {
+		t.Fatalf("failed to get cluster: %s", err)
+	}
+
+	return cluster
+}
+
+func assertCondition(t *testing.T, cluster *tarantoolv1alpha1.Cluster, conditionType tarantoolv1alpha1.ClusterConditionType, status corev1.ConditionStatus, reason string) {
+	condition := cluster.Status.GetCondition(conditionType)
+	if condition == nil {
+		t.Fatalf("condition %s is not set", conditionType)
+	}
+	if condition.Status != status || condition.Reason != reason {
+		t.Errorf("condition %s is %s (%s), expected %s (%s)", conditionType, condition.Status, condition.Reason, status, reason)
+	}
+}
+
+func TestReconcileBootstrapsCluster(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(2)...)
+	defer restore()
+
+	result, err := r.Reconcile(kvRequest)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.RequeueAfter != HealthCheckPeriod {
+		t.Errorf("expected health check requeue, got %+v", result)
+	}
+
+	replicasets := cartridge.Replicasets()
+	if len(replicasets) != 1 {
+		t.Fatalf("expected a single replicaset, got %+v", replicasets)
+	}
+	rs := replicasets[0]
+	if rs.UUID != "rs-uuid" || rs.Alias != "storage-0" || len(rs.Servers) != 2 || rs.Weight != 1 {
+		t.Errorf("unexpected replicaset %+v", rs)
+	}
+
+	servers := cartridge.Servers()
+	if len(servers) != 2 || servers[0].URI != "storage-0-0.kv.default.svc.cluster.local:3301" {
+		t.Errorf("unexpected servers %+v", servers)
+	}
+	if servers[0].Buckets != fake.BucketCount {
+		t.Errorf("expected master to store %d buckets, got %d", fake.BucketCount, servers[0].Buckets)
+	}
+
+	if !cartridge.Bootstrapped() {
+		t.Error("expected vshard to be bootstrapped")
+	}
+	if mode := cartridge.Failover().Mode; mode != "eventual" {
+		t.Errorf("expected eventual failover, got %s", mode)
+	}
+
+	for _, name := range []string{"storage-0-0", "storage-0-1"} {
+		pod := &corev1.Pod{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, pod); err != nil {
+			t.Fatalf("failed to get pod %s: %s", name, err)
+		}
+		if !tarantool.IsJoined(pod) || !tarantool.HasFinalizer(pod) {
+			t.Errorf("expected pod %s to be joined and hold the finalizer", name)
+		}
+	}
+
+	cluster := getKVCluster(t, r.client)
+	if cluster.Status.Leader != "storage-0-0.kv.default.svc.cluster.local:8081" {
+		t.Errorf("unexpected leader %q", cluster.Status.Leader)
+	}
+	if len(cluster.Status.Servers) != 2 {
+		t.Errorf("expected 2 servers in status, got %+v", cluster.Status.Servers)
+	}
+	assertCondition(t, cluster, tarantoolv1alpha1.ClusterBootstrapped, corev1.ConditionTrue, "Bootstrapped")
+	assertCondition(t, cluster, tarantoolv1alpha1.ClusterHealthy, corev1.ConditionTrue, "Healthy")
+	assertCondition(t, cluster, tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionTrue, "InSync")
+	assertCondition(t, cluster, tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionTrue, "Updated")
+
+	// a converged cluster is left as it is
+	mutations := len(cartridge.Mutations())
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, mutation := range cartridge.Mutations()[mutations:] {
+		t.Errorf("unexpected %s mutation on a converged cluster", mutation)
+	}
+}
+
+func getPod(t *testing.T, c client.Client, name string) *corev1.Pod {
+	pod := &corev1.Pod{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, pod); err != nil {
+		t.Fatalf("failed to get pod %s: %s", name, err)
+	}
+
+	return pod
+}
+
+// markDeleted sets the deletion timestamp the apiserver sets on a pod
+// deleted while it holds finalizers
+func markDeleted(t *testing.T, c client.Client, name string) {
+	pod := getPod(t, c, name)
+	now := metav1.Now()
+	pod.SetDeletionTimestamp(&now)
+	if err := c.Update(context.TODO(), pod); err != nil {
+		t.Fatalf("failed to delete pod %s: %s", name, err)
+	}
+}
+
+func TestReconcileKeepsRestartedPod(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(2)...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the pod is within the replica count, StatefulSet brings it back
+	markDeleted(t, r.client, "storage-0-1")
+	calls := len(cartridge.Calls())
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if pod := getPod(t, r.client, "storage-0-1"); tarantool.HasFinalizer(pod) || tarantool.IsExpelling(pod) {
+		t.Error("expected restarted pod to be released without expelling")
+	}
+	for _, call := range cartridge.Calls()[calls:] {
+		if call == "expel_server" {
+			t.Error("unexpected expel of a restarted pod")
+		}
+	}
+	if servers := cartridge.Servers(); len(servers) != 2 {
+		t.Errorf("expected restarted instance to stay in the topology, got %+v", servers)
+	}
+}
+
+func TestReconcileExpelsPodBeyondReplicas(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(2)...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-0"}, sts); err != nil {
+		t.Fatalf("failed to get statefulset: %s", err)
+	}
+	replicas := int32(1)
+	sts.Spec.Replicas = &replicas
+	if err := r.client.Update(context.TODO(), sts); err != nil {
+		t.Fatalf("failed to scale statefulset: %s", err)
+	}
+	markDeleted(t, r.client, "storage-0-1")
+
+	// the finalizer is held while the expel fails
+	cartridge.Inject(fake.Fault{Operation: "expel_server", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if _, err := r.Reconcile(kvRequest); err == nil {
+		t.Fatal("expected failed expel to be retried")
+	}
+	pod := getPod(t, r.client, "storage-0-1")
+	if !tarantool.HasFinalizer(pod) || !tarantool.IsExpelling(pod) {
+		t.Fatal("expected pod to hold the finalizer until it is expelled")
+	}
+	if servers := cartridge.Servers(); len(servers) != 2 {
+		t.Fatalf("expected instance to stay in the topology, got %+v", servers)
+	}
+
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pod := getPod(t, r.client, "storage-0-1"); tarantool.HasFinalizer(pod) {
+		t.Error("expected finalizer to be dropped once the pod is expelled")
+	}
+	if servers := cartridge.Servers(); len(servers) != 1 || servers[0].Alias != "storage-0-0" {
+		t.Errorf("expected storage-0-1 to be expelled, got %+v", servers)
+	}
+}
+
+func TestReconcileReleasesPodsOfDeletedCluster(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(2)...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the Cluster is being deleted
+	cluster := getKVCluster(t, r.client)
+	now := metav1.Now()
+	cluster.SetDeletionTimestamp(&now)
+	if err := r.client.Update(context.TODO(), cluster); err != nil {
+		t.Fatalf("failed to delete cluster: %s", err)
+	}
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pod := getPod(t, r.client, "storage-0-0"); tarantool.HasFinalizer(pod) {
+		t.Error("expected pods of a deleted cluster to be released")
+	}
+
+	// the Cluster is gone before its pods
+	for _, name := range []string{"storage-0-0", "storage-0-1"} {
+		pod := getPod(t, r.client, name)
+		tarantool.AddFinalizer(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			t.Fatalf("failed to update pod %s: %s", name, err)
+		}
+	}
+	if err := r.client.Delete(context.TODO(), cluster); err != nil {
+		t.Fatalf("failed to delete cluster: %s", err)
+	}
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, name := range []string{"storage-0-0", "storage-0-1"} {
+		if pod := getPod(t, r.client, name); tarantool.HasFinalizer(pod) {
+			t.Errorf("expected pod %s to be released", name)
+		}
+	}
+	if servers := cartridge.Servers(); len(servers) != 2 {
+		t.Errorf("expected instances not to be expelled, got %+v", servers)
+	}
+}
+
+func getStatefulSet(t *testing.T, c client.Client, name string) *appsv1.StatefulSet {
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, sts); err != nil {
+		t.Fatalf("failed to get statefulset %s: %s", name, err)
+	}
+
+	return sts
+}
+
+func TestReconcileScalesDownReplicaset(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	objs := newKVCluster(2)
+	role := objs[1].(*tarantoolv1alpha1.Role)
+	ep := objs[len(objs)-1].(*corev1.Endpoints)
+	objs = append(objs, newKVReplicaset(role, ep, "storage-1", "rs-uuid-1", 2)...)
+
+	r, restore := newTestReconciler(cartridge, objs...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if servers := cartridge.Servers(); len(servers) != 4 || servers[2].Buckets == 0 {
+		t.Fatalf("expected storage-1 to join and take buckets, got %+v", servers)
+	}
+
+	// role controller requests removal by setting replicaset weight to 0
+	cartridge.PauseRebalancer()
+	sts := getStatefulSet(t, r.client, "storage-1")
+	sts.Annotations["tarantool.io/removalRequested"] = "1"
+	sts.Annotations[tarantoolv1alpha1.ReplicasetWeightAnnotation] = "0"
+	if err := r.client.Update(context.TODO(), sts); err != nil {
+		t.Fatalf("failed to update statefulset: %s", err)
+	}
+
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, rs := range cartridge.Replicasets() {
+		if rs.UUID == "rs-uuid-1" && rs.Weight != 0 {
+			t.Errorf("expected storage-1 weight to be set to 0, got %v", rs.Weight)
+		}
+	}
+	if sts := getStatefulSet(t, r.client, "storage-1"); sts.Annotations["tarantool.io/scheduledDelete"] == "1" {
+		t.Fatal("expected removal to wait for buckets to move away")
+	}
+
+	// stateful failover appointed the last instance, it has to go last
+	for _, server := range cartridge.Servers() {
+		if server.Alias == "storage-1-1" {
+			if err := cartridge.SetActiveMaster(server.UUID); err != nil {
+				t.Fatalf("failed to appoint master: %s", err)
+			}
+		}
+	}
+
+	cartridge.ResumeRebalancer()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sts = getStatefulSet(t, r.client, "storage-1")
+	if sts.Annotations["tarantool.io/scheduledDelete"] != "1" {
+		t.Fatalf("expected removal to be scheduled once buckets moved away, got %v", sts.Annotations)
+	}
+	if sts.Annotations["tarantool.io/isExpelled"] == "1" {
+		t.Fatal("expected instances to be expelled on the next pass")
+	}
+
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if sts := getStatefulSet(t, r.client, "storage-1"); sts.Annotations["tarantool.io/isExpelled"] != "1" {
+		t.Errorf("expected storage-1 to be marked expelled, got %v", sts.Annotations)
+	}
+	servers := cartridge.Servers()
+	if len(servers) != 2 || servers[0].Alias != "storage-0-0" || servers[1].Alias != "storage-0-1" {
+		t.Errorf("expected storage-1 instances to be expelled, got %+v", servers)
+	}
+	for _, name := range []string{"storage-1-0", "storage-1-1"} {
+		if pod := getPod(t, r.client, name); !tarantool.IsExpelling(pod) {
+			t.Errorf("expected pod %s to be marked expelling", name)
+		}
+	}
+}
+
+// newMemtxKVCluster makes the kv cluster with instances
+// taking memtx_memory from storage-memtx ConfigMap
+func newMemtxKVCluster(grow bool) []runtime.Object {
+	objs := newKVCluster(1)
+	objs[0].(*tarantoolv1alpha1.Cluster).Spec.GrowMemtxAtRuntime = grow
+	for _, obj := range objs {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			container := corev1.Container{Name: "pim-storage"}
+			tarantool.SetMemtxConfigMapRef(&container, "storage-memtx")
+			pod.Spec.Containers = []corev1.Container{container}
+		}
+	}
+
+	return append(objs, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "storage-memtx"},
+		Data:       map[string]string{tarantool.MemtxMemoryKey: "268435456"},
+	})
+}
+
+func TestReconcileGrowsMemtx(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	// growing memtx at runtime is opt-in
+	cartridge.RegisterMemtxMutation()
+	r, restore := newTestReconciler(cartridge, newMemtxKVCluster(false)...)
+	defer restore()
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(kvRequest); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if servers := cartridge.Servers(); len(servers) != 1 || servers[0].MemtxMemory != 0 {
+		t.Errorf("expected memtx_memory to be left to restart, got %+v", servers)
+	}
+
+	r, restore = newTestReconciler(cartridge, newMemtxKVCluster(true)...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if servers := cartridge.Servers(); len(servers) != 1 || servers[0].MemtxMemory != 268435456 {
+		t.Errorf("expected memtx_memory to grow, got %+v", servers)
+	}
+
+	// applications which do not register the mutation are skipped
+	cartridge = fake.NewCartridge()
+	defer cartridge.Close()
+
+	r, restore = newTestReconciler(cartridge, newMemtxKVCluster(true)...)
+	defer restore()
+	result, err := r.Reconcile(kvRequest)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.RequeueAfter != HealthCheckPeriod {
+		t.Errorf("expected health check requeue, got %+v", result)
+	}
+	for _, call := range cartridge.Calls() {
+		if call == "set_memtx_memory" {
+			t.Error("unexpected set_memtx_memory call on an application without it")
+		}
+	}
+	if status := getKVCluster(t, r.client).Status; status.Leader == "" {
+		t.Error("expected leader to be kept")
+	}
+}
+
+func TestReconcileConvergesRoles(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	cartridge.AddRole("storage", "vshard-storage")
+	cartridge.AddRole("metrics")
+
+	objs := newKVCluster(1)
+	objs[2].(*appsv1.StatefulSet).Annotations[tarantoolv1alpha1.RolesToAssignAnnotation] = `["storage", "metrics"]`
+
+	r, restore := newTestReconciler(cartridge, objs...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertCondition(t, getKVCluster(t, r.client), tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionTrue, "InSync")
+
+	// a role removed from the replicaset is removed in cartridge
+	sts := getStatefulSet(t, r.client, "storage-0")
+	sts.Annotations[tarantoolv1alpha1.RolesToAssignAnnotation] = `["storage"]`
+	if err := r.client.Update(context.TODO(), sts); err != nil {
+		t.Fatalf("failed to update statefulset: %s", err)
+	}
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if rs := cartridge.Replicasets(); len(rs) != 1 || fmt.Sprint(rs[0].Roles) != "[storage]" {
+		t.Errorf("expected metrics role to be removed, got %+v", rs)
+	}
+	cluster := getKVCluster(t, r.client)
+	assertCondition(t, cluster, tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionTrue, "InSync")
+	if roles := cluster.Status.Replicasets[0].Roles; fmt.Sprint(roles) != "[storage vshard-storage]" {
+		t.Errorf("expected roles to be reported with dependencies, got %v", roles)
+	}
+}
+
+func TestReconcileSkipsUnreachableLeader(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	cartridge.Inject(fake.Fault{Host: "storage-0-0.kv.default.svc.cluster.local", Unreachable: true})
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(2)...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cluster := getKVCluster(t, r.client)
+	if cluster.Status.Leader != "storage-0-1.kv.default.svc.cluster.local:8081" {
+		t.Errorf("unexpected leader %q", cluster.Status.Leader)
+	}
+	if len(cartridge.Servers()) != 2 {
+		t.Errorf("expected both instances to join, got %+v", cartridge.Servers())
+	}
+}
+
+func TestReconcileRetriesTransientErrors(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	cartridge.Inject(fake.Fault{Operation: "cluster.edit_topology", StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(2)...)
+	defer restore()
+	if _, err := r.Reconcile(kvRequest); err == nil {
+		t.Fatal("expected transient error to be returned for a retry")
+	}
+	if len(cartridge.Servers()) != 0 {
+		t.Errorf("expected nobody to join, got %+v", cartridge.Servers())
+	}
+
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(cartridge.Servers()) != 2 || !cartridge.Bootstrapped() {
+		t.Errorf("expected the retry to bootstrap the cluster, got %+v", cartridge.Servers())
+	}
+}
+
+func TestReconcileReportsRejectedTopology(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	cartridge.Inject(fake.Fault{
+		Operation: "cluster.edit_topology",
+		ClassName: "Invalid cluster topology config",
+		Message:   "replicasets[rs-uuid] can not be created",
+	})
+
+	r, restore := newTestReconciler(cartridge, newKVCluster(1)...)
+	defer restore()
+	result, err := r.Reconcile(kvRequest)
+	if err != nil {
+		t.Fatalf("expected permanent error not to be retried, got %s", err)
+	}
+	if result.RequeueAfter != HealthCheckPeriod {
+		t.Errorf("expected health check requeue, got %+v", result)
+	}
+
+	cluster := getKVCluster(t, r.client)
+	assertCondition(t, cluster, tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionFalse, "Rejected")
+	if cartridge.Bootstrapped() {
+		t.Error("expected vshard not to be bootstrapped")
+	}
+}
+
+// recordingService is a backend passing calls to cartridge
+// and recording topology changes
+type recordingService struct {
+	topology.TopologyService
+	edits [][]*topology.EditReplicasetInput
+}
+
+func (s *recordingService) ApplyTopology(ctx context.Context, replicasets []*topology.EditReplicasetInput) error {
+	s.edits = append(s.edits, replicasets)
+	return s.TopologyService.ApplyTopology(ctx, replicasets)
+}
+
+// recording is the backend of the recording provider, tests reset it
+var recording = &recordingService{}
+
+func init() {
+	// providers stay registered, so it is done once for every run of the tests
+	topology.Register("recording", func(config *topology.Config) (topology.TopologyService, error) {
+		s, err := topology.New(topology.CartridgeProvider, func(c *topology.Config) { *c = *config })
+		if err != nil {
+			return nil, err
+		}
+		recording.TopologyService = s
+		return recording, nil
+	})
+}
+
+func TestReconcileWithTopologyProvider(t *testing.T) {
+	cartridge := fake.NewCartridge()
+	defer cartridge.Close()
+
+	recording = &recordingService{}
+
+	objs := newKVCluster(2)
+	objs[0].(*tarantoolv1alpha1.Cluster).Spec.Topology = &tarantoolv1alpha1.TopologySpec{Provider: "recording"}
+
+	r, restore := newTestReconciler(cartridge, objs...)
+	defer restore()
+
+	if _, err := r.Reconcile(kvRequest); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(recording.edits) != 1 || len(recording.edits[0]) != 1 || len(recording.edits[0][0].JoinServers) != 2 {
+		t.Errorf("expected both instances to join through the plugged backend, got %+v", recording.edits)
+	}
+	if !cartridge.Bootstrapped() {
+		t.Error("expected vshard to be bootstrapped")
+	}
+
+	objs = newKVCluster(2)
+	objs[0].(*tarantoolv1alpha1.Cluster).Spec.Topology = &tarantoolv1alpha1.TopologySpec{Provider: "unknown"}
+
+	r, restore = newTestReconciler(cartridge, objs...)
+	defer restore()
+
+	if _, err := r.Reconcile(kvRequest); err == nil {
+		t.Fatal("expected unknown provider to fail the pass")
+	}
+}
@@ -0,0 +1,314 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcileFailover converges cartridge failover configuration to the desired one
+// and reports the outcome as the FailoverConfigured condition
+func (r *ReconcileCluster) reconcileFailover(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, roleList *tarantoolv1alpha1.RoleList, topologyClient topology.TopologyService, status *tarantoolv1alpha1.ClusterStatus) error {
+	reqLogger := log.WithValues("Request.Namespace", cluster.GetNamespace(), "Request.Name", cluster.GetName())
+
+	desired, err := r.getDesiredFailover(cluster, roleList)
+	if err != nil {
+		status.SetCondition(tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionFalse, "InvalidConfiguration", err.Error())
+		return err
+	}
+
+	if desired == nil {
+		status.SetCondition(tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionUnknown, "NotManaged", "spec.failover is not set")
+		return nil
+	}
+
+	current, err := topologyClient.GetFailoverParams(ctx)
+	if err != nil {
+		status.SetCondition(tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionUnknown, "TopologyUnavailable", err.Error())
+		return err
+	}
+
+	if IsFailoverConverged(desired, current) {
+		status.SetCondition(tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionTrue, "Converged", fmt.Sprintf("%s failover is configured", desired.Mode))
+		return nil
+	}
+
+	reqLogger.Info("failover params changed, run update", "mode", desired.Mode, "stateProvider", desired.StateProvider)
+	if err := topologyClient.SetFailoverParams(ctx, desired); err != nil {
+		status.SetCondition(tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionFalse, "ConfigurationFailed", err.Error())
+		return err
+	}
+
+	status.SetCondition(tarantoolv1alpha1.ClusterFailoverConfigured, corev1.ConditionTrue, "Updated", fmt.Sprintf("%s failover is configured", desired.Mode))
+	return nil
+}
+
+// getDesiredFailover builds cartridge failover params from Cluster spec,
+// nil means failover is not managed by the operator
+func (r *ReconcileCluster) getDesiredFailover(cluster *tarantoolv1alpha1.Cluster, roleList *tarantoolv1alpha1.RoleList) (*topology.FailoverParams, error) {
+	spec := cluster.Spec.Failover
+	if spec == nil {
+		return r.getLegacyFailover(cluster, roleList)
+	}
+
+	params := &topology.FailoverParams{
+		Mode:           spec.Mode,
+		FencingEnabled: spec.FencingEnabled,
+	}
+
+	if spec.FailoverTimeout != nil {
+		params.FailoverTimeout = float64(*spec.FailoverTimeout)
+	}
+	if spec.FencingTimeout != nil {
+		params.FencingTimeout = float64(*spec.FencingTimeout)
+	}
+	if spec.FencingPause != nil {
+		params.FencingPause = float64(*spec.FencingPause)
+	}
+
+	switch spec.Mode {
+	case "disabled", "eventual":
+		return params, nil
+	case "stateful":
+	default:
+		return nil, fmt.Errorf("unknown failover mode %q", spec.Mode)
+	}
+
+	params.StateProvider = spec.StateProvider
+	switch spec.StateProvider {
+	case "tarantool":
+		if spec.Tarantool == nil {
+			return nil, fmt.Errorf("failover.tarantool is required by tarantool state provider")
+		}
+
+		password, err := r.getSecretValue(cluster.GetNamespace(), spec.Tarantool.PasswordSecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		params.TarantoolParams = &topology.TarantoolParams{
+			URI:      spec.Tarantool.URI,
+			Password: password,
+		}
+	case "etcd2":
+		if spec.Etcd2 == nil {
+			return nil, fmt.Errorf("failover.etcd2 is required by etcd2 state provider")
+		}
+
+		password, err := r.getSecretValue(cluster.GetNamespace(), spec.Etcd2.PasswordSecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Etcd2Params = &topology.Etcd2Params{
+			Endpoints: spec.Etcd2.Endpoints,
+			Prefix:    spec.Etcd2.Prefix,
+			Username:  spec.Etcd2.Username,
+			Password:  password,
+		}
+		if spec.Etcd2.LockDelay != nil {
+			params.Etcd2Params.LockDelay = float64(*spec.Etcd2.LockDelay)
+		}
+	default:
+		return nil, fmt.Errorf("unknown failover state provider %q", spec.StateProvider)
+	}
+
+	return params, nil
+}
+
+// getLegacyFailover reads failover configuration the way it was done before
+// spec.failover: the mode from tarantool.io/failoverMode Role annotation and
+// state provider parameters from cluster-config ConfigMap or the Secret named
+// by tarantool.io/failoverSecret annotation of the same Role
+func (r *ReconcileCluster) getLegacyFailover(cluster *tarantoolv1alpha1.Cluster, roleList *tarantoolv1alpha1.RoleList) (*topology.FailoverParams, error) {
+	failoverMode := ""
+	annotations := map[string]string{}
+	for _, role := range roleList.Items {
+		if mode, ok := role.GetAnnotations()[tarantoolv1alpha1.FailoverModeAnnotation]; ok && mode != "" {
+			failoverMode = mode
+			annotations = role.GetAnnotations()
+			break
+		}
+	}
+
+	if failoverMode == "" {
+		return nil, nil
+	}
+
+	log.Info("tarantool.io/failoverMode annotation is deprecated, use Cluster spec.failover", "mode", failoverMode)
+
+	switch failoverMode {
+	case "eventual":
+		return &topology.FailoverParams{Mode: "eventual"}, nil
+	case "stateful-tarantool":
+		name := types.NamespacedName{
+			Namespace: cluster.GetNamespace(),
+			Name:      "cluster-config",
+		}
+
+		configmap := &corev1.ConfigMap{}
+		if err := r.client.Get(context.TODO(), name, configmap); err != nil {
+			return nil, err
+		}
+
+		var stateboardURI = "stateboard:3301"
+		if val, ok := configmap.Data["stateboardUri"]; ok {
+			stateboardURI = val
+		}
+
+		// there is no safe default for the password
+		stateboardPassword, ok := configmap.Data["stateboardPassword"]
+		if !ok || stateboardPassword == "" {
+			return nil, fmt.Errorf("stateboardPassword is not set in %s ConfigMap", name.Name)
+		}
+
+		return &topology.FailoverParams{
+			Mode:          "stateful",
+			StateProvider: "tarantool",
+			TarantoolParams: &topology.TarantoolParams{
+				URI:      stateboardURI,
+				Password: stateboardPassword,
+			},
+		}, nil
+	case "stateful-etcd2":
+		secretName, ok := annotations[tarantoolv1alpha1.FailoverSecretAnnotation]
+		if !ok || secretName == "" {
+			return nil, fmt.Errorf("%s annotation is required by stateful-etcd2 failover mode", tarantoolv1alpha1.FailoverSecretAnnotation)
+		}
+
+		secret := &corev1.Secret{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.GetNamespace(), Name: secretName}, secret); err != nil {
+			return nil, err
+		}
+
+		params, err := GetEtcd2Params(secret)
+		if err != nil {
+			return nil, err
+		}
+
+		return &topology.FailoverParams{
+			Mode:          "stateful",
+			StateProvider: "etcd2",
+			Etcd2Params:   &params,
+		}, nil
+	case "stateful-consul":
+		// Roles annotated before the mode was rejected at admission
+		return nil, fmt.Errorf("failover mode %q is not supported, cartridge keeps failover state in a stateboard or etcd2 only", failoverMode)
+	}
+
+	return nil, fmt.Errorf("unknown failover mode %q", failoverMode)
+}
+
+// getSecretValue reads a single Secret key, nil selector reads as an empty value
+func (r *ReconcileCluster) getSecretValue(namespace string, selector *corev1.SecretKeySelector) (string, error) {
+	if selector == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: selector.Name}, secret); err != nil {
+		return "", err
+	}
+
+	val, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret %q", selector.Key, selector.Name)
+	}
+
+	return string(val), nil
+}
+
+// GetEtcd2Params reads etcd2 failover state provider parameters from Secret:
+// etcd2Endpoints is a comma separated list, etcd2LockDelay is in seconds
+func GetEtcd2Params(secret *corev1.Secret) (topology.Etcd2Params, error) {
+	params := topology.Etcd2Params{}
+
+	if val, ok := secret.Data["etcd2Endpoints"]; ok {
+		for _, endpoint := range strings.Split(string(val), ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				params.Endpoints = append(params.Endpoints, endpoint)
+			}
+		}
+	}
+	if val, ok := secret.Data["etcd2Prefix"]; ok {
+		params.Prefix = string(val)
+	}
+	if val, ok := secret.Data["etcd2LockDelay"]; ok {
+		lockDelay, err := strconv.ParseFloat(string(val), 64)
+		if err != nil {
+			return params, err
+		}
+		params.LockDelay = lockDelay
+	}
+	if val, ok := secret.Data["etcd2Username"]; ok {
+		params.Username = string(val)
+	}
+	if val, ok := secret.Data["etcd2Password"]; ok {
+		params.Password = string(val)
+	}
+
+	return params, nil
+}
+
+// IsFailoverConverged tells whether cartridge failover configuration matches
+// the desired one, parameters left unset in desired are not compared
+func IsFailoverConverged(desired *topology.FailoverParams, current *topology.FailoverParams) bool {
+	if desired.Mode != current.Mode || desired.FencingEnabled != current.FencingEnabled {
+		return false
+	}
+
+	if desired.FailoverTimeout > 0 && desired.FailoverTimeout != current.FailoverTimeout {
+		return false
+	}
+	if desired.FencingTimeout > 0 && desired.FencingTimeout != current.FencingTimeout {
+		return false
+	}
+	if desired.FencingPause > 0 && desired.FencingPause != current.FencingPause {
+		return false
+	}
+
+	if desired.Mode != "stateful" {
+		return true
+	}
+
+	if desired.StateProvider != current.StateProvider {
+		return false
+	}
+
+	if desired.TarantoolParams != nil {
+		if current.TarantoolParams == nil || *desired.TarantoolParams != *current.TarantoolParams {
+			return false
+		}
+	}
+
+	if desired.Etcd2Params != nil {
+		d, c := desired.Etcd2Params, current.Etcd2Params
+		if c == nil {
+			return false
+		}
+		if len(d.Endpoints) > 0 && !reflect.DeepEqual(d.Endpoints, c.Endpoints) {
+			return false
+		}
+		if d.Prefix != "" && d.Prefix != c.Prefix {
+			return false
+		}
+		if d.LockDelay > 0 && d.LockDelay != c.LockDelay {
+			return false
+		}
+		if d.Username != "" && d.Username != c.Username {
+			return false
+		}
+		if d.Password != "" && d.Password != c.Password {
+			return false
+		}
+	}
+
+	return true
+}
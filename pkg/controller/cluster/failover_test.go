@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	"github.com/tarantool/tarantool-operator/pkg/topology/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type failoverConvergedTestCase struct {
+	desired  *topology.FailoverParams
+	current  *topology.FailoverParams
+	expected bool
+}
+
+func TestIsFailoverConverged(t *testing.T) {
+	stateboard := &topology.FailoverParams{
+		Mode:          "stateful",
+		StateProvider: "tarantool",
+		TarantoolParams: &topology.TarantoolParams{
+			URI:      "stateboard:3301",
+			Password: "secret",
+		},
+	}
+
+	cases := []failoverConvergedTestCase{
+		{
+			desired:  &topology.FailoverParams{Mode: "eventual"},
+			current:  &topology.FailoverParams{Mode: "disabled"},
+			expected: false,
+		},
+		{
+			desired:  &topology.FailoverParams{Mode: "eventual"},
+			current:  &topology.FailoverParams{Mode: "eventual", FailoverTimeout: 20, StateProvider: "tarantool"},
+			expected: true,
+		},
+		{
+			desired:  &topology.FailoverParams{Mode: "eventual", FailoverTimeout: 30},
+			current:  &topology.FailoverParams{Mode: "eventual", FailoverTimeout: 20},
+			expected: false,
+		},
+		{
+			desired: stateboard,
+			current: &topology.FailoverParams{
+				Mode:            "stateful",
+				StateProvider:   "tarantool",
+				TarantoolParams: &topology.TarantoolParams{URI: "stateboard:3301", Password: "secret"},
+			},
+			expected: true,
+		},
+		{
+			desired: stateboard,
+			current: &topology.FailoverParams{
+				Mode:            "stateful",
+				StateProvider:   "tarantool",
+				TarantoolParams: &topology.TarantoolParams{URI: "stateboard:3301", Password: "rotated"},
+			},
+			expected: false,
+		},
+		{
+			desired: stateboard,
+			current: &topology.FailoverParams{
+				Mode:          "stateful",
+				StateProvider: "etcd2",
+				Etcd2Params:   &topology.Etcd2Params{Prefix: "/"},
+			},
+			expected: false,
+		},
+		{
+			desired: &topology.FailoverParams{
+				Mode:          "stateful",
+				StateProvider: "etcd2",
+				Etcd2Params:   &topology.Etcd2Params{Endpoints: []string{"http://etcd:2379"}},
+			},
+			current: &topology.FailoverParams{
+				Mode:          "stateful",
+				StateProvider: "etcd2",
+				Etcd2Params:   &topology.Etcd2Params{Endpoints: []string{"http://etcd:2379"}, Prefix: "/", LockDelay: 10},
+			},
+			expected: true,
+		},
+	}
+
+	for i, c := range cases {
+		if IsFailoverConverged(c.desired, c.current) != c.expected {
+			t.Fatalf("%d: expected converged to be %t", i, c.expected)
+		}
+	}
+}
+
+type legacyFailoverTestCase struct {
+	annotations map[string]string
+	objs        []runtime.Object
+	expected    *topology.FailoverParams
+	expectedErr string
+}
+
+func newClusterConfig(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cluster-config"},
+		Data:       data,
+	}
+}
+
+func TestGetLegacyFailover(t *testing.T) {
+	cluster := &tarantoolv1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kv"}}
+	etcd2Secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kv-etcd2"},
+		Data: map[string][]byte{
+			"etcd2Endpoints": []byte("http://etcd-0:2379, http://etcd-1:2379"),
+			"etcd2Prefix":    []byte("/kv"),
+		},
+	}
+
+	cases := []legacyFailoverTestCase{
+		{
+			annotations: map[string]string{},
+			expected:    nil,
+		},
+		{
+			annotations: map[string]string{"tarantool.io/failoverMode": "eventual"},
+			expected:    &topology.FailoverParams{Mode: "eventual"},
+		},
+		{
+			annotations: map[string]string{"tarantool.io/failoverMode": "stateful-tarantool"},
+			objs:        []runtime.Object{newClusterConfig(map[string]string{"stateboardPassword": "secret"})},
+			expected: &topology.FailoverParams{
+				Mode:            "stateful",
+				StateProvider:   "tarantool",
+				TarantoolParams: &topology.TarantoolParams{URI: "stateboard:3301", Password: "secret"},
+			},
+		},
+		{
+			annotations: map[string]string{"tarantool.io/failoverMode": "stateful-tarantool"},
+			objs:        []runtime.Object{newClusterConfig(map[string]string{"stateboardUri": "stateboard:3301"})},
+			expectedErr: "stateboardPassword is not set",
+		},
+		{
+			annotations: map[string]string{"tarantool.io/failoverMode": "stateful-etcd2", "tarantool.io/failoverSecret": "kv-etcd2"},
+			objs:        []runtime.Object{etcd2Secret},
+			expected: &topology.FailoverParams{
+				Mode:          "stateful",
+				StateProvider: "etcd2",
+				Etcd2Params:   &topology.Etcd2Params{Endpoints: []string{"http://etcd-0:2379", "http://etcd-1:2379"}, Prefix: "/kv"},
+			},
+		},
+		{
+			annotations: map[string]string{"tarantool.io/failoverMode": "stateful-etcd2"},
+			objs:        []runtime.Object{etcd2Secret},
+			expectedErr: "tarantool.io/failoverSecret annotation is required",
+		},
+		{
+			annotations: map[string]string{"tarantool.io/failoverMode": "stateful-consul"},
+			expectedErr: "is not supported",
+		},
+	}
+
+	for i, c := range cases {
+		roleList := &tarantoolv1alpha1.RoleList{
+			Items: []tarantoolv1alpha1.Role{{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "storage", Annotations: c.annotations}}},
+		}
+		r := &ReconcileCluster{client: fake.NewClient(c.objs...)}
+
+		params, err := r.getLegacyFailover(cluster, roleList)
+		if c.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Errorf("%d: expected error %q, got %v", i, c.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(params, c.expected) {
+			t.Errorf("%d: expected %+v, got %+v", i, c.expected, params)
+		}
+	}
+}
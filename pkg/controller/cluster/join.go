@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"context"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// prepareJoins assigns instance uuids to pods of the StatefulSet and picks
+// the ones to be joined in this pass, see pendingJoins. Joined pods get the
+// instance finalizer back if they lost it. The second value tells whether
+// every instance of the StatefulSet is going to be joined after the batch
+func (r *ReconcileCluster) prepareJoins(cluster *tarantoolv1alpha1.Cluster, sts *appsv1.StatefulSet) ([]*corev1.Pod, bool, error) {
+	reqLogger := log.WithValues("Request.Namespace", cluster.GetNamespace(), "Request.Name", cluster.GetName())
+
+	pods, err := r.getStatefulSetPods(sts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, pod := range pods {
+		if pod == nil || HasInstanceUUID(pod) {
+			continue
+		}
+
+		pod = SetInstanceUUID(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			return nil, false, err
+		}
+		reqLogger.Info("set instance uuid", "Pod.Name", pod.GetName(), "UUID", pod.GetLabels()["tarantool.io/instance-uuid"])
+	}
+
+	for _, pod := range pods {
+		if pod == nil || !tarantool.IsJoined(pod) || tarantool.HasFinalizer(pod) || pod.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		tarantool.AddFinalizer(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			return nil, false, err
+		}
+	}
+
+	batch, complete := pendingJoins(pods)
+	return batch, complete, nil
+}
+
+// pendingJoins picks StatefulSet pods to be joined in this pass. Instances of
+// a replicaset join by ordinal, so that the replicaset is created by the first
+// one, a missing or not ready pod holds the ones after it. The second value
+// tells whether every instance is going to be joined after the batch
+func pendingJoins(pods []*corev1.Pod) ([]*corev1.Pod, bool) {
+	batch := []*corev1.Pod{}
+	for _, pod := range pods {
+		if pod == nil {
+			return batch, false
+		}
+		if pod.GetDeletionTimestamp() != nil || tarantool.IsExpelling(pod) || tarantool.IsJoined(pod) {
+			continue
+		}
+		if !HasInstanceUUID(pod) || !isPodReady(pod) {
+			return batch, false
+		}
+
+		batch = append(batch, pod)
+	}
+
+	return batch, true
+}
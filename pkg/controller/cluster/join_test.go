@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newJoinPod(ordinal int, state string, ready bool) *corev1.Pod {
+	pod := newRolloutPod(ordinal, "rev", ready)
+	if state == "" {
+		delete(pod.Labels, "tarantool.io/instance-state")
+	} else {
+		pod.Labels["tarantool.io/instance-state"] = state
+	}
+
+	return pod
+}
+
+type pendingJoinsTestCase struct {
+	pods     []*corev1.Pod
+	expected []string
+	complete bool
+}
+
+func TestPendingJoins(t *testing.T) {
+	deleted := newJoinPod(1, "joined", false)
+	deleted.DeletionTimestamp = &metav1.Time{}
+
+	noUUID := newJoinPod(1, "", true)
+	delete(noUUID.Labels, "tarantool.io/instance-uuid")
+
+	cases := []pendingJoinsTestCase{
+		{
+			pods:     []*corev1.Pod{newJoinPod(0, "joined", true), newJoinPod(1, "joined", true)},
+			expected: []string{},
+			complete: true,
+		},
+		{
+			pods:     []*corev1.Pod{newJoinPod(0, "", true), newJoinPod(1, "", true), newJoinPod(2, "", true)},
+			expected: []string{"storage-0-0", "storage-0-1", "storage-0-2"},
+			complete: true,
+		},
+		{
+			pods:     []*corev1.Pod{newJoinPod(0, "joined", true), newJoinPod(1, "", false), newJoinPod(2, "", true)},
+			expected: []string{},
+			complete: false,
+		},
+		{
+			pods:     []*corev1.Pod{newJoinPod(0, "", true), nil},
+			expected: []string{"storage-0-0"},
+			complete: false,
+		},
+		{
+			pods:     []*corev1.Pod{newJoinPod(0, "joined", true), deleted, newJoinPod(2, "", true)},
+			expected: []string{"storage-0-2"},
+			complete: true,
+		},
+		{
+			pods:     []*corev1.Pod{newJoinPod(0, "", true), noUUID},
+			expected: []string{"storage-0-0"},
+			complete: false,
+		},
+	}
+
+	for i, c := range cases {
+		batch, complete := pendingJoins(c.pods)
+		if complete != c.complete {
+			t.Fatalf("%d: expected complete %t, got %t", i, c.complete, complete)
+		}
+
+		names := []string{}
+		for _, pod := range batch {
+			names = append(names, pod.GetName())
+		}
+		if fmt.Sprint(names) != fmt.Sprint(c.expected) {
+			t.Fatalf("%d: expected batch %v, got %v", i, c.expected, names)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// probeLeader asks the instance at the admin URI how it sees itself
+var probeLeader = func(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, uri string, httpClient *http.Client) (*topology.Self, error) {
+	topologyClient, err := newTopologyService(cluster, uri, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return topologyClient.GetSelf(ctx)
+}
+
+// GetLeaderURI gets the admin URI of the instance to manage the cluster
+// through: the current leader or, failing that, the first candidate which is
+// configured and healthy in cartridge. Until the cluster is formed, when
+// nobody is configured yet, a reachable unconfigured instance will do.
+// Excluded pods and pods of excluded StatefulSets are skipped
+func GetLeaderURI(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, httpClient *http.Client, endpoint *corev1.Endpoints, excluded []string, current string) (string, error) {
+	logger := log.WithValues("func", "GetLeaderURI", "Request.Namespace", cluster.GetNamespace(), "Request.Name", cluster.GetName())
+
+	formed := len(cluster.Status.Replicasets) > 0
+
+	fallback := ""
+	for _, uri := range getLeaderCandidates(cluster, endpoint, excluded, current) {
+		self, err := probeLeader(ctx, cluster, uri, httpClient)
+		if err != nil {
+			logger.Info("leader candidate is unreachable", "URI", uri, "error", err.Error())
+			continue
+		}
+
+		if self.IsConfigured() {
+			if uri != current {
+				logger.Info("Setting leader URI", "URI", uri)
+			}
+			return uri, nil
+		}
+
+		if !formed && self.UUID == "" && fallback == "" {
+			fallback = uri
+		}
+	}
+
+	if fallback != "" {
+		logger.Info("no instance is configured yet, setting leader URI", "URI", fallback)
+		return fallback, nil
+	}
+
+	return "", fmt.Errorf("no leader candidates available")
+}
+
+// getLeaderCandidates lists admin URIs of ready cluster pods,
+// the current leader goes first
+func getLeaderCandidates(cluster *tarantoolv1alpha1.Cluster, endpoint *corev1.Endpoints, excluded []string, current string) []string {
+	candidates := []string{}
+	for _, subset := range endpoint.Subsets {
+		for _, address := range subset.Addresses {
+			target := address.TargetRef
+			if target == nil {
+				continue
+			}
+
+			skip := false
+			for _, name := range excluded {
+				if target.Name == name || isStatefulSetPod(target.Name, name) {
+					skip = true
+				}
+			}
+			if skip {
+				continue
+			}
+
+			uri := tarantool.GetAdminURI(cluster, target.Name)
+			if uri == current {
+				candidates = append([]string{uri}, candidates...)
+			} else {
+				candidates = append(candidates, uri)
+			}
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type leaderTestCase struct {
+	instances map[string]*topology.Self
+	formed    bool
+	current   string
+	excluded  []string
+	expected  string
+}
+
+func TestGetLeaderURI(t *testing.T) {
+	endpoint := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{TargetRef: &corev1.ObjectReference{Name: "storage-0-0"}},
+				{TargetRef: &corev1.ObjectReference{Name: "storage-0-1"}},
+				{TargetRef: &corev1.ObjectReference{Name: "router-0-0"}},
+			},
+		}},
+	}
+
+	configured := &topology.Self{UUID: "uuid", State: "RolesConfigured"}
+	unhealthy := &topology.Self{UUID: "uuid", State: "OperationError"}
+	unconfigured := &topology.Self{State: "Unconfigured"}
+
+	uri := func(pod string) string {
+		return pod + ".kv.default.svc.cluster.local:8081"
+	}
+
+	cases := []leaderTestCase{
+		{
+			instances: map[string]*topology.Self{"storage-0-0": configured, "storage-0-1": configured, "router-0-0": configured},
+			formed:    true,
+			current:   uri("router-0-0"),
+			expected:  uri("router-0-0"),
+		},
+		{
+			instances: map[string]*topology.Self{"storage-0-0": unhealthy, "storage-0-1": configured, "router-0-0": configured},
+			formed:    true,
+			current:   uri("storage-0-0"),
+			expected:  uri("storage-0-1"),
+		},
+		{
+			instances: map[string]*topology.Self{"storage-0-0": configured, "router-0-0": configured},
+			formed:    true,
+			excluded:  []string{"storage-0"},
+			expected:  uri("router-0-0"),
+		},
+		{
+			instances: map[string]*topology.Self{"storage-0-1": unconfigured, "router-0-0": unconfigured},
+			expected:  uri("storage-0-1"),
+		},
+		{
+			instances: map[string]*topology.Self{"storage-0-1": unconfigured, "router-0-0": unhealthy},
+			formed:    true,
+			expected:  "",
+		},
+	}
+
+	defer func(probe func(context.Context, *tarantoolv1alpha1.Cluster, string, *http.Client) (*topology.Self, error)) {
+		probeLeader = probe
+	}(probeLeader)
+
+	for i, c := range cases {
+		probeLeader = func(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, target string, httpClient *http.Client) (*topology.Self, error) {
+			for pod, self := range c.instances {
+				if uri(pod) == target {
+					return self, nil
+				}
+			}
+			return nil, errors.New("connection refused")
+		}
+
+		cluster := &tarantoolv1alpha1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "kv", Namespace: "default"}}
+		if c.formed {
+			cluster.Status.Replicasets = []tarantoolv1alpha1.ClusterReplicasetStatus{{UUID: "rs"}}
+		}
+
+		leader, err := GetLeaderURI(context.Background(), cluster, http.DefaultClient, endpoint, c.excluded, c.current)
+		if c.expected == "" {
+			if err == nil {
+				t.Fatalf("%d: expected no leader, got %s", i, leader)
+			}
+			continue
+		}
+		if err != nil || leader != c.expected {
+			t.Fatalf("%d: expected leader %s, got %s (%v)", i, c.expected, leader, err)
+		}
+	}
+}
+
+func TestGetLeaderCandidates(t *testing.T) {
+	endpoint := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{TargetRef: &corev1.ObjectReference{Name: "storage-0-0"}},
+				{TargetRef: &corev1.ObjectReference{Name: "router-0-0"}},
+			},
+		}},
+	}
+
+	cluster := &tarantoolv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kv", Namespace: "default"},
+		Spec:       tarantoolv1alpha1.ClusterSpec{Domain: "example.org", HTTPPort: 8082},
+	}
+
+	candidates := getLeaderCandidates(cluster, endpoint, nil, "router-0-0.kv.default.svc.example.org:8082")
+	expected := []string{"router-0-0.kv.default.svc.example.org:8082", "storage-0-0.kv.default.svc.example.org:8082"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Fatalf("expected candidates %v, got %v", expected, candidates)
+	}
+}
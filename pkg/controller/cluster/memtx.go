@@ -0,0 +1,109 @@
+package cluster
+
+import (
+	"context"
+	"strconv"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcileMemtx grows memtx_memory of running instances up to the value of
+// ConfigMap they take it from, as long as it fits into their memory limit.
+// Shrinking is left to the next restart. It is done only for Clusters which
+// opt in and applications which register set_memtx_memory mutation
+func (r *ReconcileCluster) reconcileMemtx(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, stsList *appsv1.StatefulSetList, topologyClient topology.TopologyService) error {
+	reqLogger := log.WithValues("Request.Namespace", cluster.GetNamespace(), "Request.Name", cluster.GetName())
+
+	if !cluster.Spec.GrowMemtxAtRuntime {
+		return nil
+	}
+
+	memtxClient, ok := topologyClient.(topology.MemtxService)
+	if !ok {
+		return nil
+	}
+
+	stats, err := topologyClient.GetServerStat(ctx)
+	if err != nil {
+		return err
+	}
+
+	quota := make(map[string]int64)
+	for _, stat := range stats.Stats {
+		quota[stat.UUID] = int64(stat.Statistics.QuotaSize)
+	}
+
+	desired := make(map[string]int64)
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		if sts.GetAnnotations()["tarantool.io/removalRequested"] == "1" || sts.Spec.Replicas == nil {
+			continue
+		}
+
+		pods, err := r.getStatefulSetPods(sts)
+		if err != nil {
+			return err
+		}
+
+		for _, pod := range pods {
+			if pod == nil || pod.GetDeletionTimestamp() != nil || !tarantool.IsJoined(pod) {
+				continue
+			}
+
+			idx := tarantool.ContainerIndex(pod.GetAnnotations(), &pod.Spec)
+			if idx < 0 {
+				continue
+			}
+			container := &pod.Spec.Containers[idx]
+
+			ref := tarantool.GetMemtxConfigMapRef(container)
+			if ref == nil || ref.Key != tarantool.MemtxMemoryKey {
+				continue
+			}
+
+			memtx, ok := desired[ref.Name]
+			if !ok {
+				cm := &corev1.ConfigMap{}
+				if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: pod.GetNamespace(), Name: ref.Name}, cm); err != nil {
+					return err
+				}
+
+				memtx, err = strconv.ParseInt(cm.Data[ref.Key], 10, 64)
+				if err != nil {
+					return err
+				}
+				desired[ref.Name] = memtx
+			}
+
+			uuid := pod.GetLabels()["tarantool.io/instance-uuid"]
+			current, ok := quota[uuid]
+			if !ok || memtx <= current {
+				continue
+			}
+
+			capacity, limited, err := tarantool.GetMemtxCapacity(pod.GetAnnotations(), container)
+			if err != nil {
+				return err
+			}
+			if limited && memtx > capacity {
+				continue
+			}
+
+			reqLogger.Info("growing memtx_memory", "Pod.Name", pod.GetName(), "old", current, "new", memtx)
+			if err := memtxClient.SetMemtxMemory(ctx, uuid, memtx); err != nil {
+				if topology.IsMemtxNotSupported(err) {
+					reqLogger.Info("application does not register set_memtx_memory, memtx_memory is applied on restart")
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	return nil
+}
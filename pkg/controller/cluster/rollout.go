@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// rolloutStallTimeout is how long a single rollout step may take
+// before the replicaset is reported as stalled
+const rolloutStallTimeout = 10 * time.Minute
+
+type rolloutAction int
+
+const (
+	// rolloutDone every instance runs the update revision
+	rolloutDone rolloutAction = iota
+	// rolloutWait the replicaset is not healthy enough for the next step
+	rolloutWait
+	// rolloutRestart the pod is to be deleted and recreated from the update revision
+	rolloutRestart
+	// rolloutPromote the pod is to take over replicaset master
+	rolloutPromote
+)
+
+type rolloutStep struct {
+	action  rolloutAction
+	pod     *corev1.Pod
+	message string
+}
+
+// reconcileRollout restarts outdated instances of OnDelete StatefulSets one
+// replicaset instance at a time, replicas first and the master last, after
+// its role is handed over to an updated replica
+func (r *ReconcileCluster) reconcileRollout(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, stsList *appsv1.StatefulSetList, data *topology.ReplicaSetData, ep *corev1.Endpoints, excluded []string, topologyClient topology.TopologyService, status *tarantoolv1alpha1.ClusterStatus) error {
+	reqLogger := log.WithValues("Request.Namespace", cluster.GetNamespace(), "Request.Name", cluster.GetName())
+
+	for i := range stsList.Items {
+		// annotations might have been updated earlier in this pass
+		sts := &appsv1.StatefulSet{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: stsList.Items[i].GetNamespace(), Name: stsList.Items[i].GetName()}, sts); err != nil {
+			return err
+		}
+		if sts.GetAnnotations()["tarantool.io/removalRequested"] == "1" || sts.Spec.Replicas == nil {
+			continue
+		}
+		if sts.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+			continue
+		}
+
+		stsLogger := reqLogger.WithValues("StatefulSet.Name", sts.GetName())
+
+		pods, err := r.getStatefulSetPods(sts)
+		if err != nil {
+			return err
+		}
+
+		var rs *topology.ReplicaSet
+		for _, v := range data.ReplicaSets {
+			if v.UUID == sts.GetLabels()["tarantool.io/replicaset-uuid"] {
+				rs = v
+			}
+		}
+
+		current := tarantool.GetRollout(sts)
+		next := &tarantool.Rollout{State: tarantool.RolloutInProgress, Since: time.Now()}
+
+		step := nextRolloutStep(sts, pods, rs, data.Servers)
+
+		// promotion takes effect asynchronously, do not repeat it
+		if step.action == rolloutPromote && current != nil && current.Pod == step.pod.GetName() {
+			step.action = rolloutWait
+			step.message = fmt.Sprintf("waiting for %s to take over replicaset master", step.pod.GetName())
+		}
+
+		switch step.action {
+		case rolloutDone:
+			if current == nil {
+				continue
+			}
+			stsLogger.Info("rolling update is complete")
+			next = nil
+		case rolloutWait:
+			if current == nil {
+				next.Message = step.message
+				break
+			}
+
+			next.Pod = current.Pod
+			next.Since = current.Since
+			next.Message = step.message
+			if time.Since(current.Since) > rolloutStallTimeout {
+				next.State = tarantool.RolloutStalled
+			}
+		case rolloutPromote:
+			stateful := false
+			if params, err := topologyClient.GetFailoverParams(ctx); err == nil {
+				stateful = params.Mode == "stateful"
+			}
+
+			stsLogger.Info("promoting replica before master restart", "Pod.Name", step.pod.GetName())
+			if err := topologyClient.Promote(ctx, rs.UUID, step.pod.GetLabels()["tarantool.io/instance-uuid"], stateful); err != nil {
+				return err
+			}
+
+			next.Pod = step.pod.GetName()
+			next.Message = step.message
+		case rolloutRestart:
+			if strings.HasPrefix(status.Leader, fmt.Sprintf("%s.", step.pod.GetName())) {
+				stsLogger.Info("pod to restart is the current leader, re-elect leader", "Pod.Name", step.pod.GetName())
+				httpClient, err := r.getAdminClient(cluster)
+				if err != nil {
+					return err
+				}
+
+				leader, err := GetLeaderURI(ctx, cluster, httpClient, ep, append([]string{step.pod.GetName()}, excluded...), "")
+				if err != nil {
+					return err
+				}
+
+				status.Leader = leader
+				return nil
+			}
+
+			stsLogger.Info("restarting outdated instance", "Pod.Name", step.pod.GetName())
+			if err := r.client.Delete(context.TODO(), step.pod); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+
+			next.Pod = step.pod.GetName()
+			next.Message = step.message
+		}
+
+		if next != nil && current != nil && *next == *current {
+			continue
+		}
+
+		tarantool.SetRollout(sts, next)
+		if err := r.client.Update(context.TODO(), sts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getStatefulSetPods gets StatefulSet pods by ordinal, missing pods are nil
+func (r *ReconcileCluster) getStatefulSetPods(sts *appsv1.StatefulSet) ([]*corev1.Pod, error) {
+	pods := []*corev1.Pod{}
+	for i := 0; i < int(*sts.Spec.Replicas); i++ {
+		pod := &corev1.Pod{}
+		name := types.NamespacedName{
+			Namespace: sts.GetNamespace(),
+			Name:      fmt.Sprintf("%s-%d", sts.GetName(), i),
+		}
+		if err := r.client.Get(context.TODO(), name, pod); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			pod = nil
+		}
+
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+// nextRolloutStep decides what to do next to bring replicaset instances to
+// the StatefulSet update revision. Each step requires every instance to be
+// ready, joined and healthy in cartridge. Replicas are restarted from the
+// highest ordinal, the master is restarted after an updated replica
+// takes over, a single instance replicaset restarts right away
+func nextRolloutStep(sts *appsv1.StatefulSet, pods []*corev1.Pod, rs *topology.ReplicaSet, servers []*topology.Server) rolloutStep {
+	revision := sts.Status.UpdateRevision
+	if revision == "" {
+		return rolloutStep{action: rolloutDone}
+	}
+
+	outdated := []*corev1.Pod{}
+	for i := len(pods) - 1; i >= 0; i-- {
+		if pods[i] != nil && pods[i].GetLabels()[appsv1.ControllerRevisionHashLabelKey] != revision {
+			outdated = append(outdated, pods[i])
+		}
+	}
+	if len(outdated) == 0 {
+		return rolloutStep{action: rolloutDone}
+	}
+
+	status := make(map[string]string)
+	for _, server := range servers {
+		status[server.UUID] = server.Status
+	}
+
+	for i, pod := range pods {
+		if pod == nil {
+			return rolloutStep{action: rolloutWait, message: fmt.Sprintf("waiting for pod %s-%d to be created", sts.GetName(), i)}
+		}
+		if pod.GetDeletionTimestamp() != nil {
+			return rolloutStep{action: rolloutWait, message: fmt.Sprintf("waiting for pod %s to restart", pod.GetName())}
+		}
+		if !isPodReady(pod) {
+			return rolloutStep{action: rolloutWait, message: fmt.Sprintf("waiting for pod %s to become ready", pod.GetName())}
+		}
+		if !tarantool.IsJoined(pod) {
+			return rolloutStep{action: rolloutWait, message: fmt.Sprintf("waiting for pod %s to join the cluster", pod.GetName())}
+		}
+		if status[pod.GetLabels()["tarantool.io/instance-uuid"]] != "healthy" {
+			return rolloutStep{action: rolloutWait, message: fmt.Sprintf("waiting for instance %s to become healthy", pod.GetName())}
+		}
+	}
+
+	if rs == nil {
+		return rolloutStep{action: rolloutWait, message: "waiting for the replicaset to appear in cluster topology"}
+	}
+
+	master := ""
+	if rs.ActiveMaster != nil {
+		master = rs.ActiveMaster.UUID
+	} else if rs.Master != nil {
+		master = rs.Master.UUID
+	}
+
+	for _, pod := range outdated {
+		if pod.GetLabels()["tarantool.io/instance-uuid"] != master {
+			return rolloutStep{action: rolloutRestart, pod: pod, message: fmt.Sprintf("restarting replica %s", pod.GetName())}
+		}
+	}
+
+	// only the master is left outdated
+	if len(pods) == 1 {
+		return rolloutStep{action: rolloutRestart, pod: outdated[0], message: fmt.Sprintf("restarting master %s", outdated[0].GetName())}
+	}
+
+	for i := len(pods) - 1; i >= 0; i-- {
+		if pods[i] != outdated[0] {
+			return rolloutStep{action: rolloutPromote, pod: pods[i], message: fmt.Sprintf("promoting %s to replicaset master", pods[i].GetName())}
+		}
+	}
+
+	return rolloutStep{action: rolloutDone}
+}
+
+// isPodReady tells whether the pod reports Ready condition
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRolloutPod(ordinal int, revision string, ready bool) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("storage-0-%d", ordinal),
+			Labels: map[string]string{
+				appsv1.ControllerRevisionHashLabelKey: revision,
+				"tarantool.io/instance-uuid":          fmt.Sprintf("uuid-%d", ordinal),
+				"tarantool.io/instance-state":         "joined",
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+		},
+	}
+}
+
+type rolloutStepTestCase struct {
+	pods     []*corev1.Pod
+	master   string
+	action   rolloutAction
+	expected string
+}
+
+func TestNextRolloutStep(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage-0"},
+		Status:     appsv1.StatefulSetStatus{UpdateRevision: "new"},
+	}
+
+	cases := []rolloutStepTestCase{
+		{
+			pods:   []*corev1.Pod{newRolloutPod(0, "new", true), newRolloutPod(1, "new", true)},
+			master: "uuid-0",
+			action: rolloutDone,
+		},
+		{
+			pods:     []*corev1.Pod{newRolloutPod(0, "old", true), newRolloutPod(1, "old", true), newRolloutPod(2, "old", true)},
+			master:   "uuid-0",
+			action:   rolloutRestart,
+			expected: "storage-0-2",
+		},
+		{
+			pods:   []*corev1.Pod{newRolloutPod(0, "old", true), newRolloutPod(1, "new", false)},
+			master: "uuid-0",
+			action: rolloutWait,
+		},
+		{
+			pods:   []*corev1.Pod{newRolloutPod(0, "old", true), nil},
+			master: "uuid-0",
+			action: rolloutWait,
+		},
+		{
+			pods:     []*corev1.Pod{newRolloutPod(0, "old", true), newRolloutPod(1, "new", true), newRolloutPod(2, "new", true)},
+			master:   "uuid-0",
+			action:   rolloutPromote,
+			expected: "storage-0-2",
+		},
+		{
+			pods:     []*corev1.Pod{newRolloutPod(0, "old", true), newRolloutPod(1, "new", true)},
+			master:   "uuid-1",
+			action:   rolloutRestart,
+			expected: "storage-0-0",
+		},
+		{
+			pods:     []*corev1.Pod{newRolloutPod(0, "old", true)},
+			master:   "uuid-0",
+			action:   rolloutRestart,
+			expected: "storage-0-0",
+		},
+	}
+
+	for i, c := range cases {
+		rs := &topology.ReplicaSet{UUID: "rs", ActiveMaster: &topology.ReplicasetServer{UUID: c.master}}
+		servers := []*topology.Server{}
+		for _, pod := range c.pods {
+			if pod != nil {
+				servers = append(servers, &topology.Server{UUID: pod.GetLabels()["tarantool.io/instance-uuid"], Status: "healthy"})
+			}
+		}
+
+		step := nextRolloutStep(sts, c.pods, rs, servers)
+		if step.action != c.action {
+			t.Fatalf("%d: expected action %d, got %d (%s)", i, c.action, step.action, step.message)
+		}
+		if c.expected != "" && step.pod.GetName() != c.expected {
+			t.Fatalf("%d: expected pod %s, got %s", i, c.expected, step.pod.GetName())
+		}
+	}
+
+	servers := []*topology.Server{{UUID: "uuid-0", Status: "healthy"}, {UUID: "uuid-1", Status: "unreachable"}}
+	pods := []*corev1.Pod{newRolloutPod(0, "old", true), newRolloutPod(1, "old", true)}
+	if step := nextRolloutStep(sts, pods, &topology.ReplicaSet{}, servers); step.action != rolloutWait {
+		t.Fatalf("expected to wait for unhealthy instance, got %d", step.action)
+	}
+}
@@ -0,0 +1,328 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileTopology assigns instance uuids, then brings cartridge topology
+// to the one of StatefulSets: every pod ready to join is joined and replicaset
+// weights are set, all in a single edit_topology call. It returns true when
+// there is no instance left to join
+func (r *ReconcileCluster) reconcileTopology(ctx context.Context, cluster *tarantoolv1alpha1.Cluster, stsList *appsv1.StatefulSetList, roleList *tarantoolv1alpha1.RoleList, knownRoles []*topology.KnownRole, topologyClient topology.TopologyService) (bool, error) {
+	reqLogger := log.WithValues("Request.Namespace", cluster.GetNamespace(), "Request.Name", cluster.GetName())
+
+	current, err := topologyClient.GetReplicaSetList(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	done := true
+	edits := []*topology.EditReplicasetInput{}
+	joining := []*corev1.Pod{}
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		if sts.GetAnnotations()["tarantool.io/scheduledDelete"] == "1" && sts.GetAnnotations()["tarantool.io/removalRequested"] == "1" {
+			continue
+		}
+		if sts.Spec.Replicas == nil {
+			continue
+		}
+
+		batch, complete, err := r.prepareJoins(cluster, sts)
+		if err != nil {
+			return false, err
+		}
+		if !complete {
+			done = false
+		}
+
+		desired, err := desiredReplicaset(sts, getStatefulSetRole(sts, roleList))
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range batch {
+			desired.JoinServers = append(desired.JoinServers, &topology.JoinServerInput{
+				URI:  tarantool.GetAdvertiseURI(cluster, pod.GetName()),
+				UUID: pod.GetLabels()["tarantool.io/instance-uuid"],
+			})
+		}
+
+		if edit := replicasetEdit(desired, &current.Data, knownRoles); edit != nil {
+			edits = append(edits, edit)
+		}
+		joining = append(joining, batch...)
+	}
+
+	if len(edits) > 0 {
+		reqLogger.Info("applying topology changes", "replicasets", len(edits), "instances", len(joining))
+		if err := topologyClient.ApplyTopology(ctx, edits); err != nil {
+			return false, err
+		}
+	}
+
+	// instances already known to cartridge are marked as well
+	for _, pod := range joining {
+		tarantool.MarkJoined(pod)
+		tarantool.AddFinalizer(pod)
+		if err := r.client.Update(context.TODO(), pod); err != nil {
+			return false, err
+		}
+		reqLogger.Info("joined the cluster", "Pod.Name", pod.GetName())
+	}
+
+	return done, nil
+}
+
+// getStatefulSetRole finds the Role controlling the StatefulSet
+func getStatefulSetRole(sts *appsv1.StatefulSet, roleList *tarantoolv1alpha1.RoleList) *tarantoolv1alpha1.Role {
+	owner := metav1.GetControllerOf(sts)
+	if owner == nil {
+		return nil
+	}
+
+	for i := range roleList.Items {
+		if roleList.Items[i].GetUID() == owner.UID {
+			return &roleList.Items[i]
+		}
+	}
+
+	return nil
+}
+
+// desiredReplicaset builds the replicaset of the StatefulSet as it is to be
+// in cartridge: it is named after the StatefulSet, roles come from the
+// StatefulSet or its pod template, vshard group from the pod template,
+// weight from the StatefulSet and all_rw from the Role
+func desiredReplicaset(sts *appsv1.StatefulSet, role *tarantoolv1alpha1.Role) (*topology.EditReplicasetInput, error) {
+	template := &corev1.Pod{ObjectMeta: *sts.Spec.Template.ObjectMeta.DeepCopy()}
+	if roles, ok := sts.GetAnnotations()[tarantoolv1alpha1.RolesToAssignAnnotation]; ok {
+		if template.Annotations == nil {
+			template.Annotations = make(map[string]string)
+		}
+		template.Annotations[tarantoolv1alpha1.RolesToAssignAnnotation] = roles
+	}
+
+	roles, err := topology.GetRoles(template)
+	if err != nil {
+		return nil, err
+	}
+
+	vshardGroup, err := topology.GetVshardGroup(template)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := &topology.EditReplicasetInput{
+		UUID:        sts.GetLabels()["tarantool.io/replicaset-uuid"],
+		Alias:       sts.GetName(),
+		Roles:       roles,
+		VshardGroup: vshardGroup,
+	}
+
+	if weight, err := strconv.ParseFloat(sts.GetAnnotations()["tarantool.io/replicaset-weight"], 64); err == nil {
+		desired.Weight = &weight
+	}
+
+	if role != nil {
+		allRW, _ := strconv.ParseBool(role.GetAnnotations()[tarantoolv1alpha1.AllRWAnnotation])
+		desired.AllRW = &allRW
+	}
+
+	return desired, nil
+}
+
+// replicasetEdit computes the edit_topology input bringing the replicaset
+// to the desired state, nil when there is nothing to change. A missing
+// replicaset is created as desired along with its first instances, an
+// existing one gets instances it lacks joined, its drifted fields and
+// weight updated
+func replicasetEdit(desired *topology.EditReplicasetInput, current *topology.ReplicaSetData, knownRoles []*topology.KnownRole) *topology.EditReplicasetInput {
+	known := make(map[string]bool)
+	for _, server := range current.Servers {
+		known[server.UUID] = true
+	}
+
+	joinServers := []*topology.JoinServerInput{}
+	for _, server := range desired.JoinServers {
+		if !known[server.UUID] {
+			joinServers = append(joinServers, server)
+		}
+	}
+
+	var rs *topology.ReplicaSet
+	for _, v := range current.ReplicaSets {
+		if v.UUID == desired.UUID {
+			rs = v
+		}
+	}
+
+	if rs == nil {
+		if len(joinServers) == 0 {
+			return nil
+		}
+
+		edit := *desired
+		edit.JoinServers = joinServers
+		return &edit
+	}
+
+	edit := &topology.EditReplicasetInput{UUID: desired.UUID}
+	changed := false
+	if len(joinServers) > 0 {
+		edit.JoinServers = joinServers
+		changed = true
+	}
+	if desired.Weight != nil && *desired.Weight != rs.Weight {
+		edit.Weight = desired.Weight
+		changed = true
+	}
+
+	for _, field := range replicasetDrift(desired, rs, knownRoles) {
+		switch field {
+		case "roles":
+			edit.Roles = desired.Roles
+		case "alias":
+			edit.Alias = desired.Alias
+		case "allRW":
+			edit.AllRW = desired.AllRW
+		case "vshardGroup":
+			// cartridge does not allow to move a storage to another group
+			if rs.VshardGroup != "" {
+				continue
+			}
+			edit.VshardGroup = desired.VshardGroup
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return edit
+}
+
+// replicasetDrift lists fields of the replicaset which differ from the
+// desired ones. Cartridge reports roles along with the ones they depend on,
+// so the replicaset roles are compared with the desired ones and their
+// dependencies. Roles the application does not list as known, such as
+// hidden ones, are not compared. Vshard group matters to storages only
+func replicasetDrift(desired *topology.EditReplicasetInput, rs *topology.ReplicaSet, knownRoles []*topology.KnownRole) []string {
+	drift := []string{}
+
+	enabled := enabledRoles(desired.Roles, knownRoles)
+	known := make(map[string]bool)
+	for _, role := range knownRoles {
+		known[role.Name] = true
+	}
+
+	assigned := make(map[string]bool)
+	for _, role := range rs.Roles {
+		if known[role] || enabled[role] {
+			assigned[role] = true
+		}
+	}
+	if !reflect.DeepEqual(assigned, enabled) {
+		drift = append(drift, "roles")
+	}
+
+	if desired.Alias != "" && desired.Alias != rs.Alias {
+		drift = append(drift, "alias")
+	}
+
+	if desired.AllRW != nil && *desired.AllRW != rs.AllRW {
+		drift = append(drift, "allRW")
+	}
+
+	storage := assigned["vshard-storage"]
+	for _, role := range desired.Roles {
+		if role == "vshard-storage" {
+			storage = true
+		}
+	}
+	if storage && desired.VshardGroup != "" && desired.VshardGroup != rs.VshardGroup {
+		drift = append(drift, "vshardGroup")
+	}
+
+	return drift
+}
+
+// enabledRoles expands roles with the ones they depend on, as cartridge
+// enables them
+func enabledRoles(roles []string, knownRoles []*topology.KnownRole) map[string]bool {
+	dependencies := make(map[string][]string)
+	for _, role := range knownRoles {
+		dependencies[role.Name] = role.Dependencies
+	}
+
+	enabled := make(map[string]bool)
+	var enable func(role string)
+	enable = func(role string) {
+		if enabled[role] {
+			return
+		}
+		enabled[role] = true
+		for _, dependency := range dependencies[role] {
+			enable(dependency)
+		}
+	}
+	for _, role := range roles {
+		enable(role)
+	}
+
+	return enabled
+}
+
+// SetTopologyDrift reports replicasets of Cluster status which
+// differ from their Roles and sets the TopologyInSync condition
+func SetTopologyDrift(status *tarantoolv1alpha1.ClusterStatus, stsList *appsv1.StatefulSetList, roleList *tarantoolv1alpha1.RoleList, data *topology.ReplicaSetData, knownRoles []*topology.KnownRole) {
+	drifted := []string{}
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		if sts.GetAnnotations()["tarantool.io/removalRequested"] == "1" {
+			continue
+		}
+
+		desired, err := desiredReplicaset(sts, getStatefulSetRole(sts, roleList))
+		if err != nil {
+			continue
+		}
+
+		for _, rs := range data.ReplicaSets {
+			if rs.UUID != desired.UUID {
+				continue
+			}
+
+			drift := replicasetDrift(desired, rs, knownRoles)
+			if len(drift) == 0 {
+				break
+			}
+
+			for j := range status.Replicasets {
+				if status.Replicasets[j].UUID == rs.UUID {
+					status.Replicasets[j].Drift = drift
+				}
+			}
+			drifted = append(drifted, fmt.Sprintf("%s (%s)", sts.GetName(), strings.Join(drift, ", ")))
+		}
+	}
+
+	if len(drifted) == 0 {
+		status.SetCondition(tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionTrue, "InSync", "replicasets match their roles")
+	} else {
+		sort.Strings(drifted)
+		status.SetCondition(tarantoolv1alpha1.ClusterTopologyInSync, corev1.ConditionFalse, "Drifted", fmt.Sprintf("drifted replicasets: %s", strings.Join(drifted, "; ")))
+	}
+}
@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDesiredReplicaset(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "storage-0",
+			Labels:      map[string]string{"tarantool.io/replicaset-uuid": "rs"},
+			Annotations: map[string]string{"tarantool.io/replicaset-weight": "100"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"tarantool.io/useVshardGroups": "1",
+						"tarantool.io/vshardGroupName": "hot",
+					},
+					Annotations: map[string]string{"tarantool.io/rolesToAssign": `["storage"]`},
+				},
+			},
+		},
+	}
+	role := &tarantoolv1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{tarantoolv1alpha1.AllRWAnnotation: "true"},
+		},
+	}
+
+	desired, err := desiredReplicaset(sts, role)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if desired.UUID != "rs" || fmt.Sprint(desired.Roles) != "[storage]" || desired.VshardGroup != "hot" {
+		t.Fatalf("unexpected replicaset %+v", desired)
+	}
+	if desired.Weight == nil || *desired.Weight != 100 || desired.AllRW == nil || !*desired.AllRW {
+		t.Fatalf("expected weight 100 and all_rw, got %+v", desired)
+	}
+
+	sts.Annotations["tarantool.io/rolesToAssign"] = `["storage", "metrics"]`
+	if desired, _ := desiredReplicaset(sts, role); fmt.Sprint(desired.Roles) != "[storage metrics]" {
+		t.Fatalf("StatefulSet roles must take precedence, got %v", desired.Roles)
+	}
+
+	delete(sts.Annotations, "tarantool.io/rolesToAssign")
+	delete(sts.Spec.Template.Annotations, "tarantool.io/rolesToAssign")
+	if _, err := desiredReplicaset(sts, role); err == nil {
+		t.Fatalf("expected error for replicaset without roles")
+	}
+}
+
+// testKnownRoles are roles of the test application, storage depends on vshard-storage
+var testKnownRoles = []*topology.KnownRole{
+	{Name: "storage", Dependencies: []string{"vshard-storage"}},
+	{Name: "router", Dependencies: []string{"vshard-router"}},
+	{Name: "metrics"},
+	{Name: "vshard-storage"},
+	{Name: "vshard-router"},
+}
+
+type replicasetEditTestCase struct {
+	current  *topology.ReplicaSetData
+	expected string
+}
+
+func newTestReplicaset(mutate func(rs *topology.ReplicaSet)) *topology.ReplicaSetData {
+	rs := &topology.ReplicaSet{
+		UUID:        "rs",
+		Alias:       "storage-0",
+		Roles:       []string{"storage", "vshard-storage"},
+		Weight:      100,
+		VshardGroup: "default",
+	}
+	mutate(rs)
+
+	return &topology.ReplicaSetData{
+		ReplicaSets: []*topology.ReplicaSet{rs},
+		Servers:     []*topology.Server{{UUID: "uuid-0"}, {UUID: "uuid-1"}},
+	}
+}
+
+func describeEdit(edit *topology.EditReplicasetInput) string {
+	if edit == nil {
+		return "none"
+	}
+
+	uuids := []string{}
+	for _, server := range edit.JoinServers {
+		uuids = append(uuids, server.UUID)
+	}
+
+	result := fmt.Sprintf("join %v", uuids)
+	if edit.Alias != "" {
+		result += " alias"
+	}
+	if edit.Roles != nil {
+		result += fmt.Sprintf(" roles %v", edit.Roles)
+	}
+	if edit.Weight != nil {
+		result += " weight"
+	}
+	if edit.AllRW != nil {
+		result += " allRW"
+	}
+	if edit.VshardGroup != "" {
+		result += " vshardGroup"
+	}
+
+	return result
+}
+
+func TestReplicasetEdit(t *testing.T) {
+	weight := float64(100)
+	allRW := false
+	desired := &topology.EditReplicasetInput{
+		UUID:        "rs",
+		Alias:       "storage-0",
+		Roles:       []string{"storage"},
+		AllRW:       &allRW,
+		Weight:      &weight,
+		VshardGroup: "default",
+		JoinServers: []*topology.JoinServerInput{{URI: "storage-0-0", UUID: "uuid-0"}, {URI: "storage-0-1", UUID: "uuid-1"}},
+	}
+
+	cases := []replicasetEditTestCase{
+		{
+			current:  &topology.ReplicaSetData{},
+			expected: "join [uuid-0 uuid-1] alias roles [storage] weight allRW vshardGroup",
+		},
+		{
+			current:  newTestReplicaset(func(rs *topology.ReplicaSet) {}),
+			expected: "none",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.Weight = 0
+			}),
+			expected: "join [] weight",
+		},
+		{
+			// cartridge weights are floats, a fraction is not truncated away
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.Weight = 100.5
+			}),
+			expected: "join [] weight",
+		},
+		{
+			current: &topology.ReplicaSetData{
+				ReplicaSets: newTestReplicaset(func(rs *topology.ReplicaSet) {}).ReplicaSets,
+				Servers:     []*topology.Server{{UUID: "uuid-0"}},
+			},
+			expected: "join [uuid-1]",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.Roles = []string{"router"}
+				rs.Alias = "unnamed"
+				rs.AllRW = true
+			}),
+			expected: "join [] alias roles [storage] allRW",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.Roles = []string{"metrics", "storage", "vshard-storage"}
+			}),
+			expected: "join [] roles [storage]",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.Roles = []string{"storage"}
+			}),
+			expected: "join [] roles [storage]",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.Roles = []string{"ddl-manager", "storage", "vshard-storage"}
+			}),
+			expected: "none",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.VshardGroup = ""
+			}),
+			expected: "join [] vshardGroup",
+		},
+		{
+			current: newTestReplicaset(func(rs *topology.ReplicaSet) {
+				rs.VshardGroup = "cold"
+			}),
+			expected: "none",
+		},
+	}
+
+	for i, c := range cases {
+		if result := describeEdit(replicasetEdit(desired, c.current, testKnownRoles)); result != c.expected {
+			t.Fatalf("%d: expected %s, got %s", i, c.expected, result)
+		}
+	}
+}
+
+func TestSetTopologyDrift(t *testing.T) {
+	sts := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "storage-0",
+			Labels: map[string]string{"tarantool.io/replicaset-uuid": "rs"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"tarantool.io/useVshardGroups": "0"},
+					Annotations: map[string]string{"tarantool.io/rolesToAssign": `["storage"]`},
+				},
+			},
+		},
+	}
+	stsList := &appsv1.StatefulSetList{Items: []appsv1.StatefulSet{sts}}
+
+	data := newTestReplicaset(func(rs *topology.ReplicaSet) {
+		rs.VshardGroup = "cold"
+	})
+	status := &tarantoolv1alpha1.ClusterStatus{}
+	SetTopologyStatus(status, data)
+	SetTopologyDrift(status, stsList, &tarantoolv1alpha1.RoleList{}, data, testKnownRoles)
+
+	if fmt.Sprint(status.Replicasets[0].Drift) != "[vshardGroup]" {
+		t.Fatalf("expected vshardGroup drift, got %v", status.Replicasets[0].Drift)
+	}
+	if condition := status.GetCondition(tarantoolv1alpha1.ClusterTopologyInSync); condition == nil || condition.Status != corev1.ConditionFalse {
+		t.Fatalf("expected TopologyInSync to be false, got %+v", condition)
+	}
+
+	// roles removed from the Role are a drift as well
+	data = newTestReplicaset(func(rs *topology.ReplicaSet) {
+		rs.Roles = []string{"metrics", "storage", "vshard-storage"}
+	})
+	status = &tarantoolv1alpha1.ClusterStatus{}
+	SetTopologyStatus(status, data)
+	SetTopologyDrift(status, stsList, &tarantoolv1alpha1.RoleList{}, data, testKnownRoles)
+
+	if fmt.Sprint(status.Replicasets[0].Drift) != "[roles]" {
+		t.Fatalf("expected roles drift, got %v", status.Replicasets[0].Drift)
+	}
+
+	data = newTestReplicaset(func(rs *topology.ReplicaSet) {})
+	status = &tarantoolv1alpha1.ClusterStatus{}
+	SetTopologyStatus(status, data)
+	SetTopologyDrift(status, stsList, &tarantoolv1alpha1.RoleList{}, data, testKnownRoles)
+
+	if condition := status.GetCondition(tarantoolv1alpha1.ClusterTopologyInSync); condition == nil || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("expected TopologyInSync to be true, got %+v", condition)
+	}
+}
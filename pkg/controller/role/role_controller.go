@@ -2,19 +2,27 @@ package role
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	goerrors "errors"
 
 	"github.com/google/uuid"
 	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -28,22 +36,6 @@ import (
 var log = logf.Log.WithName("controller_role")
 var space = uuid.MustParse("C4FA9F56-A49A-4384-8BEE-9A476725973F")
 
-// ResponseError .
-type ResponseError struct {
-	Message string `json:"message"`
-}
-
-// ExpelResponseData .
-type ExpelResponseData struct {
-	ExpelInstance bool `json:"expel_instance"`
-}
-
-// ExpelResponse .
-type ExpelResponse struct {
-	Errors []*ResponseError   `json:"errors,omitempty"`
-	Data   *ExpelResponseData `json:"data,omitempty"`
-}
-
 /**
 * USER ACTION REQUIRED: This is a scaffold file intended for the user to modify with their own Controller
 * business logic.  Delete these comments after modifying this file.*
@@ -52,12 +44,23 @@ type ExpelResponse struct {
 // Add creates a new Role Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileRole{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	// Events are listed on demand, do not start a cache for all of them
+	events, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileRole{client: mgr.GetClient(), events: events, scheme: mgr.GetScheme()}, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -82,6 +85,34 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// pods are owned by StatefulSets named <role>-<N>, watch them to keep
+	// ready and joined counters of Role status fresh
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			owner := metav1.GetControllerOf(a.Meta)
+			if owner == nil || owner.Kind != "StatefulSet" {
+				return nil
+			}
+
+			idx := strings.LastIndex(owner.Name, "-")
+			if idx <= 0 {
+				return nil
+			}
+
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      owner.Name[:idx],
+						Namespace: a.Meta.GetNamespace(),
+					},
+				},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
 	err = c.Watch(&source.Kind{Type: &tarantoolv1alpha1.ReplicasetTemplate{}}, &handler.EnqueueRequestsFromMapFunc{
 		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
 			rec := r.(*ReconcileRole)
@@ -114,6 +145,8 @@ type ReconcileRole struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
+	// events reads Events straight from the apiserver
+	events client.Reader
 	scheme *runtime.Scheme
 }
 
@@ -135,6 +168,19 @@ func (r *ReconcileRole) Reconcile(request reconcile.Request) (reconcile.Result,
 		return reconcile.Result{}, goerrors.New(fmt.Sprintf("Orphan role %s", role.GetName()))
 	}
 
+	// status is written back on every pass, however it ends
+	status := role.Status.DeepCopy()
+	defer func() {
+		if reflect.DeepEqual(role.Status, *status) {
+			return
+		}
+
+		role.Status = *status
+		if err := r.client.Status().Update(context.TODO(), role); err != nil {
+			reqLogger.Error(err, "failed to update role status")
+		}
+	}()
+
 	templateSelector, err := metav1.LabelSelectorAsSelector(role.Spec.Selector)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -156,51 +202,84 @@ func (r *ReconcileRole) Reconcile(request reconcile.Request) (reconcile.Result,
 	}
 
 	// ensure num of statefulsets matches user expectations
-	if len(stsList.Items) > int(*role.Spec.NumReplicasets) {
-		reqLogger.Info("Role", "more instances", *role.Spec.NumReplicasets)
-		for i := len(stsList.Items); i > int(*role.Spec.NumReplicasets); i-- {
-			sts := &appsv1.StatefulSet{}
-			sts.Name = fmt.Sprintf("%s-%d", role.Name, i-1)
-			sts.Namespace = request.Namespace
-			reqLogger.Info("ROLE DOWNSCALE", "will remove", sts.Name)
+	//
+	// scale down takes several passes: replicaset weight is set to 0, then
+	// cluster controller waits for vshard buckets to move away and expels
+	// replicaset instances, then StatefulSet is deleted
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		stsAnnotations := sts.GetAnnotations()
+		if stsAnnotations == nil {
+			stsAnnotations = make(map[string]string)
+		}
 
-			if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}, sts); err != nil {
-				if errors.IsNotFound(err) {
-					continue
-				}
+		if stsAnnotations["tarantool.io/isExpelled"] == "1" {
+			reqLogger.Info("statefulset is expelled, deleting", "sts.Name", sts.GetName())
+			if err := r.deleteStatefulSet(role, sts); err != nil {
 				return reconcile.Result{}, err
 			}
+			continue
+		}
 
-			stsAnnotations := sts.GetAnnotations()
-			if stsAnnotations["tarantool.io/scheduledDelete"] == "1" {
-				reqLogger.Info("statefulset is ready for deletion")
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(sts.GetName(), fmt.Sprintf("%s-", role.GetName())))
+		if err != nil {
+			reqLogger.Info("statefulset name does not follow role naming, skipping", "sts.Name", sts.GetName())
+			continue
+		}
+
+		if ordinal < int(GetNumReplicasets(role)) {
+			if stsAnnotations["tarantool.io/removalRequested"] == "1" && stsAnnotations["tarantool.io/scheduledDelete"] != "1" {
+				reqLogger.Info("scale down cancelled, restoring replicaset weight", "sts.Name", sts.GetName())
+				delete(stsAnnotations, "tarantool.io/removalRequested")
+				stsAnnotations["tarantool.io/replicaset-weight"] = GetRoleWeight(role)
+				sts.SetAnnotations(stsAnnotations)
+				if err := r.client.Update(context.TODO(), sts); err != nil {
+					return reconcile.Result{}, err
+				}
 			}
+			continue
+		}
 
-			// if err := r.client.Delete(context.TODO(), sts); err != nil {
-			// 	return reconcile.Result{}, err
-			// }
+		if stsAnnotations["tarantool.io/removalRequested"] != "1" {
+			reqLogger.Info("ROLE DOWNSCALE", "will remove", sts.GetName())
+			stsAnnotations["tarantool.io/removalRequested"] = "1"
+			stsAnnotations["tarantool.io/replicaset-weight"] = "0"
+			sts.SetAnnotations(stsAnnotations)
+			if err := r.client.Update(context.TODO(), sts); err != nil {
+				return reconcile.Result{}, err
+			}
 		}
 	}
 
+	if err := r.setRoleStatus(role, stsList, stsSelector, status); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	templateList := &tarantoolv1alpha1.ReplicasetTemplateList{}
 	if err := r.client.List(context.TODO(), &client.ListOptions{LabelSelector: templateSelector}, templateList); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	if len(templateList.Items) == 0 {
+		status.SetCondition(tarantoolv1alpha1.RoleTemplateFound, corev1.ConditionFalse, "NoTemplate", fmt.Sprintf("no ReplicasetTemplate matches selector %s", templateSelector))
 		return reconcile.Result{}, goerrors.New("no template")
 	}
+	status.SetCondition(tarantoolv1alpha1.RoleTemplateFound, corev1.ConditionTrue, "TemplateFound", fmt.Sprintf("using ReplicasetTemplate %s", templateList.Items[0].GetName()))
 
 	template := templateList.Items[0]
 
-	if len(stsList.Items) < int(*role.Spec.NumReplicasets) {
-		for i := 0; i < int(*role.Spec.NumReplicasets); i++ {
+	if err := r.reconcileMemtxConfigMap(role, &template); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if len(stsList.Items) < int(GetNumReplicasets(role)) {
+		for i := 0; i < int(GetNumReplicasets(role)); i++ {
 			sts := &appsv1.StatefulSet{}
 			sts.Name = fmt.Sprintf("%s-%d", role.Name, i)
 			sts.Namespace = request.Namespace
 
 			if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name}, sts); err != nil {
-				sts = CreateStatefulSetFromTemplate(i, fmt.Sprintf("%s-%d", role.Name, i), role, &template)
+				sts = CreateStatefulSetFromTemplate(fmt.Sprintf("%s-%d", role.Name, i), role, &template)
 				if err := controllerutil.SetControllerReference(role, sts, r.scheme); err != nil {
 					return reconcile.Result{}, err
 				}
@@ -212,89 +291,287 @@ func (r *ReconcileRole) Reconcile(request reconcile.Request) (reconcile.Result,
 	}
 
 	for _, sts := range stsList.Items {
-		if template.Spec.Replicas != sts.Spec.Replicas {
-			reqLogger.Info("Updating replicas count", "sts.Name", sts.GetName())
-			sts.Spec.Replicas = template.Spec.Replicas
+		if GetReplicasetPhase(&sts) == tarantoolv1alpha1.ReplicasetDeleting {
+			continue
+		}
+
+		if GetReplicasetPhase(&sts) == tarantoolv1alpha1.ReplicasetActive && sts.GetAnnotations()["tarantool.io/replicaset-weight"] != GetRoleWeight(role) {
+			reqLogger.Info("Updating replicaset weight", "sts.Name", sts.GetName())
+			if sts.Annotations == nil {
+				sts.Annotations = make(map[string]string)
+			}
+			sts.Annotations["tarantool.io/replicaset-weight"] = GetRoleWeight(role)
 			if err := r.client.Update(context.TODO(), &sts); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
 
-		if template.Spec.Template.Spec.Containers[0].Image != sts.Spec.Template.Spec.Containers[0].Image {
-			reqLogger.Info("Updating container image", "sts.Name", sts.GetName())
-			sts.Spec.Template.Spec.Containers[0].Image = template.Spec.Template.Spec.Containers[0].Image
+		if template.Spec.Replicas != nil && (sts.Spec.Replicas == nil || *template.Spec.Replicas != *sts.Spec.Replicas) {
+			reqLogger.Info("Updating replicas count", "sts.Name", sts.GetName())
+			sts.Spec.Replicas = template.Spec.Replicas
 			if err := r.client.Update(context.TODO(), &sts); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
 
-		if template.Spec.Template.Spec.Containers[0].Resources.Limits["memory"] != sts.Spec.Template.Spec.Containers[0].Resources.Limits["memory"] {
-			reqLogger.Info("Updating memory configuration", "sts.Name", sts.GetName())
-			sts.Spec.Template.Spec.Containers[0].Resources.Limits["memory"] = template.Spec.Template.Spec.Containers[0].Resources.Limits["memory"]
-			sts.Spec.Template.Spec.Containers[0].Resources.Requests["memory"] = template.Spec.Template.Spec.Containers[0].Resources.Requests["memory"]
-
-			// When we update pod resources, we also need to upate the environment variable
-			for _, tempVar := range template.Spec.Template.Spec.Containers[0].Env {
-				if tempVar.Name == "TARANTOOL_MEMTX_MEMORY" {
-					for iter, variable := range sts.Spec.Template.Spec.Containers[0].Env {
-						if variable.Name == "TARANTOOL_MEMTX_MEMORY" {
-							sts.Spec.Template.Spec.Containers[0].Env[iter].Value = tempVar.Value
-						}
-					}
+		// the live pod template is defaulted by the apiserver, so drift is
+		// detected by the hash of the template it was last converged to
+		desired := CreateStatefulSetFromTemplate(sts.GetName(), role, &template)
+		hash := desired.GetAnnotations()["tarantool.io/templateHash"]
+		if sts.GetAnnotations()["tarantool.io/templateHash"] != hash || sts.Spec.UpdateStrategy.Type != desired.Spec.UpdateStrategy.Type {
+			reqLogger.Info("ReplicasetTemplate changed, updating pod template", "sts.Name", sts.GetName(), "hash", hash)
+			// StatefulSets of earlier operator versions carry roles in the
+			// pod template, they are kept there so as not to restart
+			// instances, roles of the StatefulSet take precedence anyway
+			legacyRoles, hasLegacyRoles := sts.Spec.Template.GetAnnotations()[tarantoolv1alpha1.RolesToAssignAnnotation]
+			sts.Spec.Template = desired.Spec.Template
+			if hasLegacyRoles {
+				if sts.Spec.Template.Annotations == nil {
+					sts.Spec.Template.Annotations = make(map[string]string)
 				}
+				sts.Spec.Template.Annotations[tarantoolv1alpha1.RolesToAssignAnnotation] = legacyRoles
 			}
-
+			sts.Spec.UpdateStrategy = desired.Spec.UpdateStrategy
+			if sts.Annotations == nil {
+				sts.Annotations = make(map[string]string)
+			}
+			sts.Annotations["tarantool.io/templateHash"] = hash
 			if err := r.client.Update(context.TODO(), &sts); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
 
-		if template.Spec.Template.Spec.Containers[0].Resources.Limits["cpu"] != sts.Spec.Template.Spec.Containers[0].Resources.Limits["cpu"] {
-			reqLogger.Info("Updating cpu configuration", "sts.Name", sts.GetName())
-			sts.Spec.Template.Spec.Containers[0].Resources.Limits["cpu"] = template.Spec.Template.Spec.Containers[0].Resources.Limits["cpu"]
-			sts.Spec.Template.Spec.Containers[0].Resources.Requests["cpu"] = template.Spec.Template.Spec.Containers[0].Resources.Requests["cpu"]
-
+		if roles, ok := desired.GetAnnotations()["tarantool.io/rolesToAssign"]; ok && sts.GetAnnotations()["tarantool.io/rolesToAssign"] != roles {
+			reqLogger.Info("Role cartridge roles changed, updating replicaset", "sts.Name", sts.GetName(), "roles", roles)
+			if sts.Annotations == nil {
+				sts.Annotations = make(map[string]string)
+			}
+			sts.Annotations["tarantool.io/rolesToAssign"] = roles
 			if err := r.client.Update(context.TODO(), &sts); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
+	}
 
-		if !reflect.DeepEqual(template.Spec.Template.Spec.Containers[0].Env, sts.Spec.Template.Spec.Containers[0].Env) {
-			reqLogger.Info("environment lists do not match, do an update")
-			sts.Spec.Template.Spec.Containers[0].Env = template.Spec.Template.Spec.Containers[0].Env
+	status.ObservedGeneration = role.GetGeneration()
 
-			if err := r.client.Update(context.TODO(), &sts); err != nil {
-				return reconcile.Result{}, err
-			}
+	// pod creation failures are not watched, look for them again
+	// while some StatefulSet lacks pods
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		if sts.Spec.Replicas != nil && sts.Status.Replicas < *sts.Spec.Replicas {
+			return reconcile.Result{RequeueAfter: RejectedPodsCheckPeriod}, nil
 		}
 	}
 
 	return reconcile.Result{}, nil
 }
 
-// CreateStatefulSetFromTemplate .
-func CreateStatefulSetFromTemplate(replicasetNumber int, name string, role *tarantoolv1alpha1.Role, rs *tarantoolv1alpha1.ReplicasetTemplate) *appsv1.StatefulSet {
+// setRoleStatus fills status with the observed state of Role StatefulSets and their pods
+func (r *ReconcileRole) setRoleStatus(role *tarantoolv1alpha1.Role, stsList *appsv1.StatefulSetList, stsSelector *metav1.LabelSelector, status *tarantoolv1alpha1.RoleStatus) error {
+	selector, err := metav1.LabelSelectorAsSelector(stsSelector)
+	if err != nil {
+		return err
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{LabelSelector: selector, Namespace: role.GetNamespace()}, podList); err != nil {
+		return err
+	}
+
+	joined := make(map[string]int32)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil || owner.Kind != "StatefulSet" || !tarantool.IsJoined(pod) {
+			continue
+		}
+		joined[owner.Name]++
+	}
+
+	status.NumReplicasets = int32(len(stsList.Items))
+	status.ReadyReplicasets = 0
+	status.JoinedReplicasets = 0
+	status.Selector = selector.String()
+	status.Replicasets = nil
+
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+
+		replicas := int32(1)
+		if sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		}
+
+		rs := tarantoolv1alpha1.ReplicasetStatus{
+			Name:            sts.GetName(),
+			Phase:           GetReplicasetPhase(sts),
+			Replicas:        replicas,
+			ReadyReplicas:   sts.Status.ReadyReplicas,
+			JoinedReplicas:  joined[sts.GetName()],
+			CurrentRevision: sts.Status.CurrentRevision,
+			UpdateRevision:  sts.Status.UpdateRevision,
+			UpdatedReplicas: sts.Status.UpdatedReplicas,
+		}
+		if rollout := tarantool.GetRollout(sts); rollout != nil {
+			rs.Rollout = rollout.State
+			rs.RolloutMessage = rollout.Message
+		}
+
+		if rs.ReadyReplicas >= replicas {
+			status.ReadyReplicasets++
+		}
+		if rs.JoinedReplicas >= replicas {
+			status.JoinedReplicasets++
+		}
+
+		status.Replicasets = append(status.Replicasets, rs)
+	}
+	sort.Slice(status.Replicasets, func(i, j int) bool {
+		return status.Replicasets[i].Name < status.Replicasets[j].Name
+	})
+
+	rolling, stalled := []string{}, []string{}
+	for _, rs := range status.Replicasets {
+		switch rs.Rollout {
+		case tarantool.RolloutInProgress:
+			rolling = append(rolling, rs.Name)
+		case tarantool.RolloutStalled:
+			stalled = append(stalled, fmt.Sprintf("%s: %s", rs.Name, rs.RolloutMessage))
+		}
+	}
+	if len(stalled) > 0 {
+		status.SetCondition(tarantoolv1alpha1.RoleRollingUpdate, corev1.ConditionTrue, "Stalled", strings.Join(stalled, "; "))
+	} else if len(rolling) > 0 {
+		status.SetCondition(tarantoolv1alpha1.RoleRollingUpdate, corev1.ConditionTrue, "InProgress", fmt.Sprintf("updating replicasets: %s", strings.Join(rolling, ", ")))
+	} else {
+		status.SetCondition(tarantoolv1alpha1.RoleRollingUpdate, corev1.ConditionFalse, "UpToDate", "all replicasets run the current pod template")
+	}
+
+	rejected, err := r.getRejectedPods(stsList)
+	if err != nil {
+		return err
+	}
+	if len(rejected) > 0 {
+		status.SetCondition(tarantoolv1alpha1.RolePodsRejected, corev1.ConditionTrue, "AdmissionRejected", strings.Join(rejected, "; "))
+	} else {
+		status.SetCondition(tarantoolv1alpha1.RolePodsRejected, corev1.ConditionFalse, "Admitted", "no pod creation is rejected")
+	}
+
+	desired := GetNumReplicasets(role)
+	if status.NumReplicasets != desired {
+		status.SetCondition(tarantoolv1alpha1.RoleScaling, corev1.ConditionTrue, "Scaling", fmt.Sprintf("%d of %d replicasets exist", status.NumReplicasets, desired))
+	} else {
+		status.SetCondition(tarantoolv1alpha1.RoleScaling, corev1.ConditionFalse, "Scaled", fmt.Sprintf("%d replicasets exist", desired))
+	}
+
+	if status.NumReplicasets == desired && status.ReadyReplicasets == desired && status.JoinedReplicasets == desired {
+		status.SetCondition(tarantoolv1alpha1.RoleReady, corev1.ConditionTrue, "AllReplicasetsReady", "all replicasets are ready and joined")
+	} else {
+		status.SetCondition(tarantoolv1alpha1.RoleReady, corev1.ConditionFalse, "ReplicasetsNotReady", fmt.Sprintf("%d ready, %d joined of %d replicasets", status.ReadyReplicasets, status.JoinedReplicasets, desired))
+	}
+
+	return nil
+}
+
+// rejectedPodEventAge is how long a pod creation failure is reported for
+const rejectedPodEventAge = 10 * time.Minute
+
+// RejectedPodsCheckPeriod is how often pod creation failures are looked
+// for while a Role StatefulSet lacks pods
+const RejectedPodsCheckPeriod = 30 * time.Second
+
+// getRejectedPods collects recent pod creation failures of StatefulSets which
+// lack pods, as reported by StatefulSet controller events
+func (r *ReconcileRole) getRejectedPods(stsList *appsv1.StatefulSetList) ([]string, error) {
+	rejected := []string{}
+	if len(stsList.Items) == 0 {
+		return rejected, nil
+	}
+
+	opts := &client.ListOptions{Namespace: stsList.Items[0].GetNamespace()}
+	if err := opts.SetFieldSelector("involvedObject.kind=StatefulSet,reason=FailedCreate"); err != nil {
+		return nil, err
+	}
+
+	eventList := &corev1.EventList{}
+	if err := r.events.List(context.TODO(), opts, eventList); err != nil {
+		return nil, err
+	}
+
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		if sts.Spec.Replicas == nil || sts.Status.Replicas >= *sts.Spec.Replicas {
+			continue
+		}
+
+		var latest *corev1.Event
+		for j := range eventList.Items {
+			event := &eventList.Items[j]
+			if event.InvolvedObject.Kind != "StatefulSet" || event.InvolvedObject.Name != sts.GetName() || event.Reason != "FailedCreate" {
+				continue
+			}
+			if time.Since(event.LastTimestamp.Time) > rejectedPodEventAge {
+				continue
+			}
+			if latest == nil || event.LastTimestamp.After(latest.LastTimestamp.Time) {
+				latest = event
+			}
+		}
+
+		if latest != nil {
+			rejected = append(rejected, fmt.Sprintf("%s: %s", sts.GetName(), latest.Message))
+		}
+	}
+
+	return rejected, nil
+}
+
+// CreateStatefulSetFromTemplate builds Role StatefulSet from ReplicasetTemplate,
+// labels and annotations owned by the operator override the template ones
+func CreateStatefulSetFromTemplate(name string, role *tarantoolv1alpha1.Role, rs *tarantoolv1alpha1.ReplicasetTemplate) *appsv1.StatefulSet {
 	reqLogger := log.WithValues("func", "CreateStatefulSetFromTemplate")
 
 	sts := &appsv1.StatefulSet{
-		Spec: *rs.Spec,
+		Spec: *rs.Spec.DeepCopy(),
 	}
 
 	sts.Name = name
 	sts.Namespace = role.GetNamespace()
-	sts.ObjectMeta.Labels = role.GetLabels()
+	sts.ObjectMeta.Labels = make(map[string]string)
+	for k, v := range role.GetLabels() {
+		sts.ObjectMeta.Labels[k] = v
+	}
 
-	sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: "OnDelete"}
+	// defaults for templates stored before the defaulting webhook
+	if sts.Spec.UpdateStrategy.Type == "" {
+		sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: "OnDelete"}
+	}
 
 	reqLogger.Info("Update Strategy: %s", sts.Spec.UpdateStrategy.Type)
 
+	if sts.Spec.Template.Labels == nil {
+		sts.Spec.Template.Labels = make(map[string]string)
+	}
 	for k, v := range role.GetLabels() {
 		sts.Spec.Template.Labels[k] = v
 	}
 
-	privileged := true
-	sts.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
-		Privileged: &privileged,
+	// security context comes from the template, the operator adds
+	// privileges only when Role opts in, sidecars are left as they are
+	idx := tarantool.ContainerIndex(sts.Spec.Template.GetAnnotations(), &sts.Spec.Template.Spec)
+	if privileged, _ := strconv.ParseBool(role.GetAnnotations()[tarantoolv1alpha1.PrivilegedAnnotation]); privileged && idx >= 0 {
+		container := &sts.Spec.Template.Spec.Containers[idx]
+		if container.SecurityContext == nil {
+			container.SecurityContext = &corev1.SecurityContext{}
+		}
+		container.SecurityContext.Privileged = &privileged
+	}
+
+	// memtx_memory is taken from ConfigMap, so it can grow without
+	// changing the pod template and restarting instances
+	if _, ok, err := tarantool.GetDesiredMemtxMemory(&sts.Spec.Template); err == nil && ok {
+		tarantool.SetMemtxConfigMapRef(&sts.Spec.Template.Spec.Containers[idx], GetMemtxConfigMapName(role))
 	}
 
 	sts.Spec.ServiceName = role.GetAnnotations()["tarantool.io/cluster-id"]
@@ -312,22 +589,165 @@ func CreateStatefulSetFromTemplate(replicasetNumber int, name string, role *tara
 	}
 
 	sts.ObjectMeta.Annotations["tarantool.io/isBootstrapped"] = "0"
-	sts.ObjectMeta.Annotations["tarantool.io/replicaset-weight"] = "100"
-	sts.ObjectMeta.Annotations["tarantool.io/failoverMode"] = role.GetAnnotations()["tarantool.io/failoverMode"]
+	sts.ObjectMeta.Annotations["tarantool.io/replicaset-weight"] = GetRoleWeight(role)
 
 	sts.Spec.Template.Labels["tarantool.io/replicaset-uuid"] = replicasetUUID.String()
 	sts.Spec.Template.Labels["tarantool.io/vshardGroupName"] = role.GetLabels()["tarantool.io/role"]
 
+	// typed fields of v1alpha2 Role are stored in annotations and take
+	// precedence over labels and annotations set in the template
+	if groupName, ok := role.GetAnnotations()["tarantool.io/vshardGroupName"]; ok {
+		sts.ObjectMeta.Labels["tarantool.io/vshardGroupName"] = groupName
+		sts.Spec.Template.Labels["tarantool.io/vshardGroupName"] = groupName
+		sts.Spec.Template.Labels["tarantool.io/useVshardGroups"] = "1"
+	}
+
+	// roles are kept out of the pod template, so that changing them
+	// reconfigures replicasets without restarting instances
+	if roles, ok := role.GetAnnotations()["tarantool.io/rolesToAssign"]; ok {
+		sts.ObjectMeta.Annotations["tarantool.io/rolesToAssign"] = roles
+	}
+
+	sts.ObjectMeta.Annotations["tarantool.io/templateHash"] = GetTemplateHash(&sts.Spec.Template)
+
 	return sts
 }
 
-// RemoveFinalizer .
-func RemoveFinalizer(finalizers []string) []string {
-	newFinalizers := []string{}
-	for _, v := range finalizers {
-		if v != "tarantool.io/replicaset" {
-			newFinalizers = append(newFinalizers, v)
+// GetTemplateHash computes a hash of the pod template
+func GetTemplateHash(template *corev1.PodTemplateSpec) string {
+	hasher := fnv.New32a()
+	data, _ := json.Marshal(template)
+	hasher.Write(data)
+
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// GetMemtxConfigMapName returns the name of ConfigMap Role instances take memtx_memory from
+func GetMemtxConfigMapName(role *tarantoolv1alpha1.Role) string {
+	return fmt.Sprintf("%s-memtx", role.GetName())
+}
+
+// reconcileMemtxConfigMap keeps memtx_memory of the template in Role ConfigMap.
+// It is called before StatefulSet templates are updated, so that instances
+// restarted with larger limits of the template start with the larger value.
+// Running instances keep the value they started with
+func (r *ReconcileRole) reconcileMemtxConfigMap(role *tarantoolv1alpha1.Role, template *tarantoolv1alpha1.ReplicasetTemplate) error {
+	reqLogger := log.WithValues("Request.Namespace", role.GetNamespace(), "Request.Name", role.GetName())
+
+	desired, ok, err := tarantool.GetDesiredMemtxMemory(&template.Spec.Template)
+	if err != nil || !ok {
+		return err
+	}
+	value := strconv.FormatInt(desired, 10)
+
+	// an explicit value which does not fit into the limits of the template
+	// is rejected by the validating webhook, it is kept out when the webhook
+	// is not installed
+	podTemplate := &template.Spec.Template
+	if idx := tarantool.ContainerIndex(podTemplate.GetAnnotations(), &podTemplate.Spec); idx >= 0 {
+		capacity, limited, err := tarantool.GetMemtxCapacity(podTemplate.GetAnnotations(), &podTemplate.Spec.Containers[idx])
+		if err != nil {
+			return err
+		}
+		if limited && capacity < desired {
+			reqLogger.Info("memtx_memory does not fit into the template memory limit", "memtxMemory", desired, "capacity", capacity)
+			return nil
+		}
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: role.GetNamespace(), Name: GetMemtxConfigMapName(role)}, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		cm.Name = GetMemtxConfigMapName(role)
+		cm.Namespace = role.GetNamespace()
+		cm.Labels = make(map[string]string)
+		for k, v := range role.GetLabels() {
+			cm.Labels[k] = v
+		}
+		cm.Data = map[string]string{tarantool.MemtxMemoryKey: value}
+		if err := controllerutil.SetControllerReference(role, cm, r.scheme); err != nil {
+			return err
+		}
+
+		return r.client.Create(context.TODO(), cm)
+	}
+
+	if cm.Data[tarantool.MemtxMemoryKey] == value {
+		return nil
+	}
+
+	reqLogger.Info("Updating memtx_memory", "old", cm.Data[tarantool.MemtxMemoryKey], "new", value)
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[tarantool.MemtxMemoryKey] = value
+
+	return r.client.Update(context.TODO(), cm)
+}
+
+// GetNumReplicasets gets the number of replicasets of Role, 1 if it is not
+// set, as Roles created without the admission webhooks may not have it
+func GetNumReplicasets(role *tarantoolv1alpha1.Role) int32 {
+	if role.Spec.NumReplicasets == nil {
+		return 1
+	}
+
+	return *role.Spec.NumReplicasets
+}
+
+// GetRoleWeight returns vshard weight of Role replicasets, 100 unless set by tarantool.io/replicaset-weight
+func GetRoleWeight(role *tarantoolv1alpha1.Role) string {
+	if weight, ok := role.GetAnnotations()["tarantool.io/replicaset-weight"]; ok {
+		if _, err := strconv.Atoi(weight); err == nil {
+			return weight
+		}
+	}
+
+	return "100"
+}
+
+// GetReplicasetPhase reports scale down progress of StatefulSet
+func GetReplicasetPhase(sts *appsv1.StatefulSet) tarantoolv1alpha1.ReplicasetPhase {
+	stsAnnotations := sts.GetAnnotations()
+
+	if stsAnnotations["tarantool.io/isExpelled"] == "1" {
+		return tarantoolv1alpha1.ReplicasetDeleting
+	}
+
+	if stsAnnotations["tarantool.io/removalRequested"] == "1" {
+		if stsAnnotations["tarantool.io/scheduledDelete"] == "1" {
+			return tarantoolv1alpha1.ReplicasetExpelling
+		}
+		return tarantoolv1alpha1.ReplicasetDraining
+	}
+
+	return tarantoolv1alpha1.ReplicasetActive
+}
+
+// deleteStatefulSet removes StatefulSet and, if Role asks so, its PersistentVolumeClaims
+func (r *ReconcileRole) deleteStatefulSet(role *tarantoolv1alpha1.Role, sts *appsv1.StatefulSet) error {
+	if err := r.client.Delete(context.TODO(), sts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if !role.Spec.DeleteVolumeClaims || sts.Spec.Replicas == nil {
+		return nil
+	}
+
+	for _, claim := range sts.Spec.VolumeClaimTemplates {
+		for i := 0; i < int(*sts.Spec.Replicas); i++ {
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvc.Name = fmt.Sprintf("%s-%s-%d", claim.GetName(), sts.GetName(), i)
+			pvc.Namespace = sts.GetNamespace()
+
+			if err := r.client.Delete(context.TODO(), pvc); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
 		}
 	}
-	return newFinalizers
+
+	return nil
 }
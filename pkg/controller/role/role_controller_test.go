@@ -0,0 +1,406 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/topology/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestTemplate(image string) *tarantoolv1alpha1.ReplicasetTemplate {
+	replicas := int32(2)
+	return &tarantoolv1alpha1.ReplicasetTemplate{
+		Spec: &appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"tarantool.io/useVshardGroups": "0"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "pim-storage", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func TestCreateStatefulSetFromTemplate(t *testing.T) {
+	role := &tarantoolv1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "storage",
+			Namespace:   "default",
+			Labels:      map[string]string{"tarantool.io/role": "storage"},
+			Annotations: map[string]string{"tarantool.io/cluster-id": "examples-kv-cluster"},
+		},
+	}
+
+	sts := CreateStatefulSetFromTemplate("storage-0", role, newTestTemplate("kv:1.0"))
+
+	if _, ok := role.GetLabels()["tarantool.io/replicaset-uuid"]; ok {
+		t.Fatalf("role labels must not be modified")
+	}
+	if sts.GetLabels()["tarantool.io/replicaset-uuid"] != sts.Spec.Template.GetLabels()["tarantool.io/replicaset-uuid"] {
+		t.Fatalf("pod template must carry replicaset uuid label")
+	}
+	if sts.Spec.ServiceName != "examples-kv-cluster" {
+		t.Fatalf("expected service name examples-kv-cluster, got %s", sts.Spec.ServiceName)
+	}
+
+	hash := sts.GetAnnotations()["tarantool.io/templateHash"]
+	if hash == "" {
+		t.Fatalf("template hash must be set")
+	}
+	if again := CreateStatefulSetFromTemplate("storage-0", role, newTestTemplate("kv:1.0")); again.GetAnnotations()["tarantool.io/templateHash"] != hash {
+		t.Fatalf("template hash must be stable")
+	}
+	if updated := CreateStatefulSetFromTemplate("storage-0", role, newTestTemplate("kv:1.1")); updated.GetAnnotations()["tarantool.io/templateHash"] == hash {
+		t.Fatalf("template hash must change with the image")
+	}
+
+	role.Annotations["tarantool.io/rolesToAssign"] = `["storage"]`
+	withRoles := CreateStatefulSetFromTemplate("storage-0", role, newTestTemplate("kv:1.0"))
+	if withRoles.GetAnnotations()["tarantool.io/rolesToAssign"] != `["storage"]` {
+		t.Fatalf("roles must be set on StatefulSet, got %v", withRoles.GetAnnotations())
+	}
+	if withRoles.GetAnnotations()["tarantool.io/templateHash"] != hash {
+		t.Fatalf("roles must not change the pod template")
+	}
+
+	template := newTestTemplate("kv:1.0")
+	template.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("320Mi")}
+	env := CreateStatefulSetFromTemplate("storage-0", role, template).Spec.Template.Spec.Containers[0].Env
+	if len(env) != 1 || env[0].ValueFrom == nil || env[0].ValueFrom.ConfigMapKeyRef.Name != "storage-memtx" {
+		t.Fatalf("memtx_memory must be taken from storage-memtx ConfigMap, got %v", env)
+	}
+}
+
+// newTestRole makes the storage Role of the kv cluster and its template
+func newTestRole(numReplicasets int32) (*tarantoolv1alpha1.Role, *tarantoolv1alpha1.ReplicasetTemplate) {
+	role := &tarantoolv1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "storage",
+			Namespace:   "default",
+			UID:         "role-uid",
+			Labels:      map[string]string{"tarantool.io/cluster-id": "kv", "tarantool.io/role": "storage"},
+			Annotations: map[string]string{"tarantool.io/cluster-id": "kv"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "tarantool.io/v1alpha1", Kind: "Cluster", Name: "kv", UID: "cluster-uid"},
+			},
+		},
+		Spec: tarantoolv1alpha1.RoleSpec{
+			NumReplicasets: &numReplicasets,
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"tarantool.io/replicaset-template": "storage-template"}},
+		},
+	}
+
+	template := newTestTemplate("kv:1.0")
+	template.ObjectMeta = metav1.ObjectMeta{
+		Name:      "storage-template",
+		Namespace: "default",
+		Labels:    map[string]string{"tarantool.io/replicaset-template": "storage-template"},
+	}
+
+	return role, template
+}
+
+func TestReconcileRole(t *testing.T) {
+	role, template := newTestRole(2)
+
+	c := fake.NewClient(role, template)
+	r := &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "storage"}}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	stsList := &appsv1.StatefulSetList{}
+	if err := c.List(context.TODO(), &client.ListOptions{Namespace: "default"}, stsList); err != nil {
+		t.Fatalf("failed to list StatefulSets: %s", err)
+	}
+	if len(stsList.Items) != 2 {
+		t.Fatalf("expected 2 StatefulSets, got %d", len(stsList.Items))
+	}
+	for _, sts := range stsList.Items {
+		if !metav1.IsControlledBy(&sts, role) {
+			t.Errorf("StatefulSet %s must be controlled by the role", sts.GetName())
+		}
+		if *sts.Spec.Replicas != 2 {
+			t.Errorf("StatefulSet %s must have 2 replicas, got %d", sts.GetName(), *sts.Spec.Replicas)
+		}
+	}
+
+	// scale down starts with moving buckets away
+	scaled := &tarantoolv1alpha1.Role{}
+	if err := c.Get(context.TODO(), request.NamespacedName, scaled); err != nil {
+		t.Fatalf("failed to get role: %s", err)
+	}
+	*scaled.Spec.NumReplicasets = 1
+	if err := c.Update(context.TODO(), scaled); err != nil {
+		t.Fatalf("failed to update role: %s", err)
+	}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-1"}, sts); err != nil {
+		t.Fatalf("failed to get StatefulSet: %s", err)
+	}
+	if sts.GetAnnotations()["tarantool.io/removalRequested"] != "1" || sts.GetAnnotations()["tarantool.io/replicaset-weight"] != "0" {
+		t.Errorf("expected storage-1 removal to be requested, got %v", sts.GetAnnotations())
+	}
+}
+
+func TestReconcileRoleWithoutNumReplicasets(t *testing.T) {
+	role, template := newTestRole(0)
+	role.Spec.NumReplicasets = nil
+
+	c := fake.NewClient(role, template)
+	r := &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "storage"}}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	stsList := &appsv1.StatefulSetList{}
+	if err := c.List(context.TODO(), &client.ListOptions{Namespace: "default"}, stsList); err != nil {
+		t.Fatalf("failed to list StatefulSets: %s", err)
+	}
+	if len(stsList.Items) != 1 {
+		t.Fatalf("expected a single StatefulSet, got %d", len(stsList.Items))
+	}
+}
+
+func TestReconcileRoleGrowsMemtxOnRestart(t *testing.T) {
+	role, template := newTestRole(1)
+	template.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("320Mi")}
+
+	// a running instance with the limits it was started with
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage-0-0", Namespace: "default", Labels: role.GetLabels()},
+		Spec:       *template.Spec.Template.Spec.DeepCopy(),
+	}
+
+	c := fake.NewClient(role, template, running)
+	r := &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "storage"}}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// limits are raised, runtime growth is not enabled, so the
+	// instance has to start with the new value once it is restarted
+	updated := &tarantoolv1alpha1.ReplicasetTemplate{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-template"}, updated); err != nil {
+		t.Fatalf("failed to get template: %s", err)
+	}
+	updated.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("640Mi")}
+	if err := c.Update(context.TODO(), updated); err != nil {
+		t.Fatalf("failed to update template: %s", err)
+	}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-0"}, sts); err != nil {
+		t.Fatalf("failed to get StatefulSet: %s", err)
+	}
+	container := sts.Spec.Template.Spec.Containers[0]
+	if limit := container.Resources.Limits[corev1.ResourceMemory]; limit.String() != "640Mi" {
+		t.Fatalf("expected pod template with raised limit, got %s", limit.String())
+	}
+	if len(container.Env) != 1 || container.Env[0].ValueFrom == nil || container.Env[0].ValueFrom.ConfigMapKeyRef == nil {
+		t.Fatalf("expected memtx_memory taken from ConfigMap, got %v", container.Env)
+	}
+
+	ref := container.Env[0].ValueFrom.ConfigMapKeyRef
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: ref.Name}, cm); err != nil {
+		t.Fatalf("failed to get ConfigMap: %s", err)
+	}
+	if expected := "536870912"; cm.Data[ref.Key] != expected {
+		t.Errorf("expected restarted instance to start with memtx_memory %s, got %s", expected, cm.Data[ref.Key])
+	}
+}
+
+func TestReconcileRoleKeepsLegacyRoles(t *testing.T) {
+	role, template := newTestRole(1)
+	role.Annotations["tarantool.io/rolesToAssign"] = `["storage"]`
+
+	// StatefulSet of an earlier operator version: roles in the pod template, no hash
+	legacy := CreateStatefulSetFromTemplate("storage-0", role, template)
+	legacy.Namespace = "default"
+	legacy.Spec.Template.Annotations = map[string]string{"tarantool.io/rolesToAssign": `["storage"]`}
+	delete(legacy.Annotations, "tarantool.io/templateHash")
+	legacy.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(role, tarantoolv1alpha1.SchemeGroupVersion.WithKind("Role"))}
+
+	c := fake.NewClient(role, template, legacy)
+	r := &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "storage"}}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-0"}, sts); err != nil {
+		t.Fatalf("failed to get StatefulSet: %s", err)
+	}
+	if sts.GetAnnotations()["tarantool.io/templateHash"] == "" {
+		t.Errorf("expected template hash to be set, got %v", sts.GetAnnotations())
+	}
+	if sts.Spec.Template.GetAnnotations()["tarantool.io/rolesToAssign"] != `["storage"]` {
+		t.Errorf("expected legacy roles to be kept in the pod template, got %v", sts.Spec.Template.GetAnnotations())
+	}
+}
+
+func TestReconcileRoleRemovesReplicaset(t *testing.T) {
+	role, template := newTestRole(1)
+	role.Spec.DeleteVolumeClaims = true
+
+	replicas := int32(2)
+	newStatefulSet := func(name string, annotations map[string]string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          role.GetLabels(),
+				Annotations:     annotations,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(role, tarantoolv1alpha1.SchemeGroupVersion.WithKind("Role"))},
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:             &replicas,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "www"}}},
+			},
+		}
+	}
+	newClaim := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	c := fake.NewClient(
+		role, template,
+		newStatefulSet("storage-0", map[string]string{"tarantool.io/replicaset-weight": "1"}),
+		newStatefulSet("storage-1", map[string]string{"tarantool.io/removalRequested": "1", "tarantool.io/scheduledDelete": "1", "tarantool.io/isExpelled": "1"}),
+		newClaim("www-storage-0-0"), newClaim("www-storage-1-0"), newClaim("www-storage-1-1"),
+	)
+	r := &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "storage"}}
+
+	// expelled replicaset is deleted along with its volumes
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-1"}, &appsv1.StatefulSet{}); !errors.IsNotFound(err) {
+		t.Errorf("expected expelled StatefulSet to be deleted, got %v", err)
+	}
+	for name, deleted := range map[string]bool{"www-storage-0-0": false, "www-storage-1-0": true, "www-storage-1-1": true} {
+		err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, &corev1.PersistentVolumeClaim{})
+		if deleted && !errors.IsNotFound(err) {
+			t.Errorf("expected claim %s to be deleted, got %v", name, err)
+		}
+		if !deleted && err != nil {
+			t.Errorf("expected claim %s to be kept, got %v", name, err)
+		}
+	}
+
+	// scale down is cancelled while buckets are moving away
+	// and goes on once instances are being expelled
+	c = fake.NewClient(
+		role, template,
+		newStatefulSet("storage-0", map[string]string{"tarantool.io/replicaset-weight": "1"}),
+		newStatefulSet("storage-1", map[string]string{"tarantool.io/removalRequested": "1", "tarantool.io/replicaset-weight": "0"}),
+		newStatefulSet("storage-2", map[string]string{"tarantool.io/removalRequested": "1", "tarantool.io/scheduledDelete": "1", "tarantool.io/replicaset-weight": "0"}),
+	)
+	r = &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+
+	scaled := role.DeepCopy()
+	*scaled.Spec.NumReplicasets = 3
+	if err := c.Update(context.TODO(), scaled); err != nil {
+		t.Fatalf("failed to update role: %s", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-1"}, sts); err != nil {
+		t.Fatalf("failed to get StatefulSet: %s", err)
+	}
+	if sts.GetAnnotations()["tarantool.io/removalRequested"] == "1" || sts.GetAnnotations()["tarantool.io/replicaset-weight"] != GetRoleWeight(role) {
+		t.Errorf("expected storage-1 removal to be cancelled, got %v", sts.GetAnnotations())
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "storage-2"}, sts); err != nil {
+		t.Fatalf("failed to get StatefulSet: %s", err)
+	}
+	if sts.GetAnnotations()["tarantool.io/removalRequested"] != "1" || sts.GetAnnotations()["tarantool.io/replicaset-weight"] != "0" {
+		t.Errorf("expected storage-2 removal to go on, got %v", sts.GetAnnotations())
+	}
+}
+
+func TestReconcileRoleReportsRejectedPods(t *testing.T) {
+	role, template := newTestRole(1)
+
+	c := fake.NewClient(role, template)
+	r := &ReconcileRole{client: c, events: c, scheme: scheme.Scheme}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "storage"}}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	now := metav1.Now()
+	for _, event := range []*corev1.Event{
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "storage-0.rejected", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "StatefulSet", Name: "storage-0", Namespace: "default"},
+			Reason:         "FailedCreate",
+			Message:        "pods \"storage-0-0\" is forbidden",
+			LastTimestamp:  now,
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "storage-0.created", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "StatefulSet", Name: "storage-0", Namespace: "default"},
+			Reason:         "SuccessfulCreate",
+			LastTimestamp:  now,
+		},
+	} {
+		if err := c.Create(context.TODO(), event); err != nil {
+			t.Fatalf("failed to create event: %s", err)
+		}
+	}
+
+	// the StatefulSet has no pods, the events are not watched
+	result, err := r.Reconcile(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.RequeueAfter != RejectedPodsCheckPeriod {
+		t.Fatalf("expected requeue after %s while pods are missing, got %v", RejectedPodsCheckPeriod, result)
+	}
+
+	updated := &tarantoolv1alpha1.Role{}
+	if err := c.Get(context.TODO(), request.NamespacedName, updated); err != nil {
+		t.Fatalf("failed to get role: %s", err)
+	}
+	cond := updated.Status.GetCondition(tarantoolv1alpha1.RolePodsRejected)
+	if cond == nil || cond.Status != corev1.ConditionTrue || cond.Message != "storage-0: pods \"storage-0-0\" is forbidden" {
+		t.Fatalf("expected storage-0 pods to be rejected, got %+v", cond)
+	}
+}
@@ -0,0 +1,59 @@
+package tarantool
+
+import (
+	"fmt"
+
+	tarantoolv1alpha1 "github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+)
+
+// operator-level defaults for clusters which do not set their own
+var (
+	// DefaultClusterDomain is the DNS domain of the Kubernetes cluster
+	DefaultClusterDomain = "cluster.local"
+	// DefaultBinaryPort is the port instances listen and advertise on
+	DefaultBinaryPort int32 = 3301
+	// DefaultHTTPPort is the port of the cartridge admin API
+	DefaultHTTPPort int32 = 8081
+)
+
+// GetClusterDomain gets the DNS domain instances of the cluster are resolved in
+func GetClusterDomain(cluster *tarantoolv1alpha1.Cluster) string {
+	if cluster.Spec.Domain != "" {
+		return cluster.Spec.Domain
+	}
+
+	return DefaultClusterDomain
+}
+
+// GetBinaryPort gets the port cluster instances advertise
+func GetBinaryPort(cluster *tarantoolv1alpha1.Cluster) int32 {
+	if cluster.Spec.BinaryPort != 0 {
+		return cluster.Spec.BinaryPort
+	}
+
+	return DefaultBinaryPort
+}
+
+// GetHTTPPort gets the port of cluster instances admin API
+func GetHTTPPort(cluster *tarantoolv1alpha1.Cluster) int32 {
+	if cluster.Spec.HTTPPort != 0 {
+		return cluster.Spec.HTTPPort
+	}
+
+	return DefaultHTTPPort
+}
+
+// GetInstanceHost gets the DNS name of the pod behind the cluster headless Service
+func GetInstanceHost(cluster *tarantoolv1alpha1.Cluster, podName string) string {
+	return fmt.Sprintf("%s.%s.%s.svc.%s", podName, cluster.GetName(), cluster.GetNamespace(), GetClusterDomain(cluster))
+}
+
+// GetAdvertiseURI gets the URI the pod instance is known by in cartridge
+func GetAdvertiseURI(cluster *tarantoolv1alpha1.Cluster, podName string) string {
+	return fmt.Sprintf("%s:%d", GetInstanceHost(cluster, podName), GetBinaryPort(cluster))
+}
+
+// GetAdminURI gets the host:port of the pod instance admin API
+func GetAdminURI(cluster *tarantoolv1alpha1.Cluster, podName string) string {
+	return fmt.Sprintf("%s:%d", GetInstanceHost(cluster, podName), GetHTTPPort(cluster))
+}
@@ -0,0 +1,34 @@
+package tarantool
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ContainerNameAnnotation is a pod template annotation naming the Tarantool container
+	ContainerNameAnnotation = "tarantool.io/tarantoolContainerName"
+	// DefaultContainerName is the Tarantool container name used when the annotation is not set
+	DefaultContainerName = "tarantool"
+)
+
+// ContainerIndex finds the Tarantool container of a pod: the one named by
+// the annotation, else the one named tarantool, else the first one.
+// -1 means the annotation names a missing container or there are no containers
+func ContainerIndex(annotations map[string]string, spec *corev1.PodSpec) int {
+	name, ok := annotations[ContainerNameAnnotation]
+	if !ok {
+		name = DefaultContainerName
+	}
+
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			return i
+		}
+	}
+
+	if ok || len(spec.Containers) == 0 {
+		return -1
+	}
+
+	return 0
+}
@@ -9,6 +9,8 @@ import (
 const (
 	instanceJoined    = "joined"
 	instanceExpelling = "expelling"
+
+	instanceFinalizer = "tarantool.io/replicaset"
 )
 
 // IsJoined .
@@ -88,3 +90,33 @@ func ExpellingSelector() (labels.Selector, error) {
 
 	return s, nil
 }
+
+// HasFinalizer .
+func HasFinalizer(p *corev1.Pod) bool {
+	for _, v := range p.GetFinalizers() {
+		if v == instanceFinalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddFinalizer .
+func AddFinalizer(p *corev1.Pod) {
+	if HasFinalizer(p) {
+		return
+	}
+	p.SetFinalizers(append(p.GetFinalizers(), instanceFinalizer))
+}
+
+// RemoveFinalizer .
+func RemoveFinalizer(p *corev1.Pod) {
+	finalizers := []string{}
+	for _, v := range p.GetFinalizers() {
+		if v != instanceFinalizer {
+			finalizers = append(finalizers, v)
+		}
+	}
+	p.SetFinalizers(finalizers)
+}
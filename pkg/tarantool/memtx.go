@@ -0,0 +1,124 @@
+package tarantool
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MemtxMemoryEnv is the environment variable Tarantool reads memtx_memory from
+	MemtxMemoryEnv = "TARANTOOL_MEMTX_MEMORY"
+	// MemtxMemoryKey is the ConfigMap key MemtxMemoryEnv is taken from
+	MemtxMemoryKey = "memtxMemory"
+	// MemtxOverheadAnnotation is a pod template annotation with the share of the
+	// container memory limit kept out of memtx for Lua, network buffers and the like
+	MemtxOverheadAnnotation = "tarantool.io/memtxOverheadRatio"
+	// DefaultMemtxOverhead is the overhead ratio used when the annotation is not set
+	DefaultMemtxOverhead = 0.2
+)
+
+// GetMemtxOverhead reads memtx overhead ratio from pod annotations
+func GetMemtxOverhead(annotations map[string]string) (float64, error) {
+	val, ok := annotations[MemtxOverheadAnnotation]
+	if !ok {
+		return DefaultMemtxOverhead, nil
+	}
+
+	ratio, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, err
+	}
+	if ratio < 0 || ratio >= 1 {
+		return 0, fmt.Errorf("overhead ratio must be in [0, 1), got %s", val)
+	}
+
+	return ratio, nil
+}
+
+// GetMemtxCapacity computes the largest memtx_memory which fits into the
+// container memory limit, false means the container has no memory limit
+func GetMemtxCapacity(annotations map[string]string, container *corev1.Container) (int64, bool, error) {
+	limit, ok := container.Resources.Limits[corev1.ResourceMemory]
+	if !ok {
+		return 0, false, nil
+	}
+
+	ratio, err := GetMemtxOverhead(annotations)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return int64(float64(limit.Value()) * (1 - ratio)), true, nil
+}
+
+// GetMemtxMemory reads memtx_memory set explicitly in the container
+// environment, false means it is not set or is taken from elsewhere
+func GetMemtxMemory(container *corev1.Container) (int64, bool, error) {
+	for _, env := range container.Env {
+		if env.Name != MemtxMemoryEnv || env.ValueFrom != nil {
+			continue
+		}
+
+		memtx, err := strconv.ParseInt(env.Value, 10, 64)
+		if err != nil {
+			return 0, false, err
+		}
+
+		return memtx, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// GetDesiredMemtxMemory gets memtx_memory of the pod template: the explicit
+// one or the one derived from the memory limit, false means neither is set
+func GetDesiredMemtxMemory(template *corev1.PodTemplateSpec) (int64, bool, error) {
+	idx := ContainerIndex(template.GetAnnotations(), &template.Spec)
+	if idx < 0 {
+		return 0, false, nil
+	}
+	container := &template.Spec.Containers[idx]
+
+	memtx, ok, err := GetMemtxMemory(container)
+	if err != nil || ok {
+		return memtx, ok, err
+	}
+
+	return GetMemtxCapacity(template.GetAnnotations(), container)
+}
+
+// GetMemtxConfigMapRef finds the ConfigMap the container takes
+// memtx_memory from, nil if there is none
+func GetMemtxConfigMapRef(container *corev1.Container) *corev1.ConfigMapKeySelector {
+	for _, env := range container.Env {
+		if env.Name == MemtxMemoryEnv && env.ValueFrom != nil {
+			return env.ValueFrom.ConfigMapKeyRef
+		}
+	}
+
+	return nil
+}
+
+// SetMemtxConfigMapRef makes the container take memtx_memory from the ConfigMap
+func SetMemtxConfigMapRef(container *corev1.Container, name string) {
+	env := corev1.EnvVar{
+		Name: MemtxMemoryEnv,
+		ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  MemtxMemoryKey,
+			},
+		},
+	}
+
+	for i := range container.Env {
+		if container.Env[i].Name == MemtxMemoryEnv {
+			container.Env[i] = env
+			return
+		}
+	}
+
+	container.Env = append(container.Env, env)
+}
@@ -1 +1,76 @@
 package tarantool
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	rolloutPodAnnotation     = "tarantool.io/rolloutPod"
+	rolloutStateAnnotation   = "tarantool.io/rolloutState"
+	rolloutMessageAnnotation = "tarantool.io/rolloutMessage"
+	rolloutSinceAnnotation   = "tarantool.io/rolloutSince"
+)
+
+const (
+	// RolloutInProgress outdated instances of the replicaset are being restarted
+	RolloutInProgress = "InProgress"
+	// RolloutStalled the current rollout step has not completed in time
+	RolloutStalled = "Stalled"
+)
+
+// Rollout is the progress of an operator driven rolling update of
+// a replicaset, it is kept in StatefulSet annotations
+type Rollout struct {
+	// Pod is the instance restarted or promoted by the current step
+	Pod     string
+	State   string
+	Message string
+	// Since is the time the current step started at
+	Since time.Time
+}
+
+// GetRollout reads rollout progress of the StatefulSet, nil means
+// no rollout is in progress
+func GetRollout(sts *appsv1.StatefulSet) *Rollout {
+	annotations := sts.GetAnnotations()
+	state, ok := annotations[rolloutStateAnnotation]
+	if !ok {
+		return nil
+	}
+
+	rollout := &Rollout{
+		Pod:     annotations[rolloutPodAnnotation],
+		State:   state,
+		Message: annotations[rolloutMessageAnnotation],
+	}
+	if since, err := time.Parse(time.RFC3339, annotations[rolloutSinceAnnotation]); err == nil {
+		rollout.Since = since
+	}
+
+	return rollout
+}
+
+// SetRollout stores rollout progress in StatefulSet annotations,
+// nil rollout removes them
+func SetRollout(sts *appsv1.StatefulSet, rollout *Rollout) {
+	annotations := sts.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if rollout == nil {
+		delete(annotations, rolloutPodAnnotation)
+		delete(annotations, rolloutStateAnnotation)
+		delete(annotations, rolloutMessageAnnotation)
+		delete(annotations, rolloutSinceAnnotation)
+	} else {
+		annotations[rolloutPodAnnotation] = rollout.Pod
+		annotations[rolloutStateAnnotation] = rollout.State
+		annotations[rolloutMessageAnnotation] = rollout.Message
+		annotations[rolloutSinceAnnotation] = rollout.Since.UTC().Format(time.RFC3339)
+	}
+
+	sts.SetAnnotations(annotations)
+}
@@ -8,71 +8,147 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/machinebox/graphql"
 	corev1 "k8s.io/api/core/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
 
-// ResponseError .
-type ResponseError struct {
-	Message string `json:"message"`
-}
-
 // JoinResponseData .
 type JoinResponseData struct {
 	JoinInstance bool `json:"joinInstanceResponse"`
 }
 
-// JoinResponse .
-type JoinResponse struct {
-	Errors []*ResponseError  `json:"errors,omitempty"`
-	Data   *JoinResponseData `json:"data,omitempty"`
-}
-
 // ExpelResponseData .
 type ExpelResponseData struct {
 	ExpelInstance bool `json:"expel_instance"`
 }
 
-// ExpelResponse .
-type ExpelResponse struct {
-	Errors []*ResponseError   `json:"errors,omitempty"`
-	Data   *ExpelResponseData `json:"data,omitempty"`
-}
-
 // BootstrapVshardData .
 type BootstrapVshardData struct {
 	BootstrapVshard bool `json:"bootstrapVshardResponse"`
 }
 
-// BootstrapVshardResponse .
-type BootstrapVshardResponse struct {
-	Data   *BootstrapVshardData `json:"data,omitempty"`
-	Errors []*ResponseError     `json:"errors,omitempty"`
-}
-
 // FailoverData Structure of data for changing failover status
 type FailoverData struct {
-	failover *FailoverParams `json:"failover_params"`
+	Cluster *FailoverClusterData `json:"cluster"`
 }
 
-// FailoverParams returns the mode of failover which has been enabled
+// FailoverClusterData .
+type FailoverClusterData struct {
+	Failover *FailoverParams `json:"failover_params"`
+}
+
+// FailoverParams is the failover configuration as cartridge reports and accepts it
 type FailoverParams struct {
-	mode string `json:"mode"`
+	Mode            string           `json:"mode"`
+	StateProvider   string           `json:"state_provider,omitempty"`
+	FailoverTimeout float64          `json:"failover_timeout,omitempty"`
+	FencingEnabled  bool             `json:"fencing_enabled"`
+	FencingTimeout  float64          `json:"fencing_timeout,omitempty"`
+	FencingPause    float64          `json:"fencing_pause,omitempty"`
+	TarantoolParams *TarantoolParams `json:"tarantool_params,omitempty"`
+	Etcd2Params     *Etcd2Params     `json:"etcd2_params,omitempty"`
+}
+
+// TarantoolParams configures stateboard state provider of stateful failover
+type TarantoolParams struct {
+	URI      string `json:"uri"`
+	Password string `json:"password"`
 }
 
-// FailoverResponse type struct for returning on failovers
-type FailoverResponse struct {
-	Data   *FailoverData
-	Errors []*ResponseError
+// Etcd2Params configures etcd2 state provider of stateful failover,
+// empty fields are left to cartridge defaults
+type Etcd2Params struct {
+	Endpoints []string `json:"endpoints,omitempty"`
+	Prefix    string   `json:"prefix,omitempty"`
+	LockDelay float64  `json:"lock_delay,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Password  string   `json:"password,omitempty"`
 }
 
+// blank assignment to verify that BuiltInTopologyService implements TopologyService
+// and MemtxService
+var _ TopologyService = &BuiltInTopologyService{}
+var _ MemtxService = &BuiltInTopologyService{}
+
 // BuiltInTopologyService .
 type BuiltInTopologyService struct {
-	serviceHost string
-	clusterID   string
+	serviceHost  string
+	advertiseURI func(pod *corev1.Pod) string
+	httpClient   *http.Client
+	client       *http.Client
+	tracker      *failureTracker
+	timeouts     Timeouts
+	// memtxSupported tells whether the application registers
+	// set_memtx_memory, nil until the schema is checked
+	memtxSupported *bool
+}
+
+// failureTracker is a transport which remembers the first admin
+// call that got no response or a server error
+type failureTracker struct {
+	transport http.RoundTripper
+	mu        sync.Mutex
+	err       error
+}
+
+// RoundTrip .
+func (t *failureTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		t.fail(fmt.Errorf("%s responded with %s", req.URL.Host, resp.Status))
+	} else if err != nil && req.Context().Err() != context.Canceled {
+		// a call cancelled by the caller tells nothing about the instance
+		t.fail(err)
+	}
+
+	return resp, err
+}
+
+func (t *failureTracker) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// Self is the instance serving admin API as it sees itself
+type Self struct {
+	URI   string `json:"uri"`
+	UUID  string `json:"uuid"`
+	Alias string `json:"alias"`
+	State string `json:"state"`
+}
+
+// SelfData .
+type SelfData struct {
+	Cluster *SelfClusterData `json:"cluster"`
+}
+
+// SelfClusterData .
+type SelfClusterData struct {
+	Self *Self `json:"self"`
+}
+
+// KnownRole is a role the application provides, cartridge enables its
+// dependencies along with it
+type KnownRole struct {
+	Name         string   `json:"name"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// KnownRolesData .
+type KnownRolesData struct {
+	Cluster *KnownRolesClusterData `json:"cluster"`
+}
+
+// KnownRolesClusterData .
+type KnownRolesClusterData struct {
+	KnownRoles []*KnownRole `json:"known_roles"`
 }
 
 // EditReplicasetResponse .
@@ -80,10 +156,63 @@ type EditReplicasetResponse struct {
 	Response bool `json:"editReplicasetResponse"`
 }
 
-// GetServerStatResponse .
-type GetServerStatResponse struct {
-	Data   *ServerStatData  `json:"data"`
-	Errors []*ResponseError `json:"errors,omitempty"`
+// SetMemtxMemoryResponse .
+type SetMemtxMemoryResponse struct {
+	Response bool `json:"setMemtxMemoryResponse"`
+}
+
+// SchemaData .
+type SchemaData struct {
+	Schema *Schema `json:"__schema"`
+}
+
+// Schema lists mutations the admin API serves
+type Schema struct {
+	MutationType *SchemaType `json:"mutationType"`
+}
+
+// SchemaType .
+type SchemaType struct {
+	Fields []*SchemaField `json:"fields"`
+}
+
+// SchemaField .
+type SchemaField struct {
+	Name string `json:"name"`
+}
+
+// EditReplicasetInput is the state of a replicaset edit_topology brings it
+// to, empty fields are left unchanged, replicasets missing in the cluster are
+// created with instances listed in JoinServers
+type EditReplicasetInput struct {
+	UUID        string             `json:"uuid"`
+	Alias       string             `json:"alias,omitempty"`
+	Roles       []string           `json:"roles,omitempty"`
+	AllRW       *bool              `json:"all_rw,omitempty"`
+	Weight      *float64           `json:"weight,omitempty"`
+	VshardGroup string             `json:"vshard_group,omitempty"`
+	JoinServers []*JoinServerInput `json:"join_servers,omitempty"`
+}
+
+// JoinServerInput .
+type JoinServerInput struct {
+	URI  string `json:"uri"`
+	UUID string `json:"uuid"`
+}
+
+// EditTopologyData .
+type EditTopologyData struct {
+	Cluster *EditTopologyClusterData `json:"cluster"`
+}
+
+// EditTopologyClusterData .
+type EditTopologyClusterData struct {
+	EditTopology *EditTopologyResult `json:"edit_topology"`
+}
+
+// EditTopologyResult .
+type EditTopologyResult struct {
+	Replicasets []*ServerReplicaset `json:"replicasets"`
 }
 
 // ServerStatData .
@@ -110,8 +239,7 @@ type Statistics struct {
 
 // ReplicasetListResponse .
 type ReplicasetListResponse struct {
-	Data   ReplicaSetData   `json:"data"`
-	Errors []*ResponseError `json"errors,omitempty"`
+	Data ReplicaSetData `json:"data"`
 }
 
 // ReplicaSetData .
@@ -122,30 +250,49 @@ type ReplicaSetData struct {
 
 // ReplicaSet .
 type ReplicaSet struct {
-	Weight      int      `json:"weight"`
-	VshardGroup string   `json:"vshard_group`
+	Weight      float64  `json:"weight"`
+	VshardGroup string   `json:"vshard_group"`
 	Alias       string   `json:"alias"`
 	Status      string   `json:"status"`
 	Roles       []string `json:"roles"`
 	UUID        string   `json:"uuid"`
 	AllRW       bool     `json:"all_rw"`
+	// Master is the first instance in the failover priority list
+	Master *ReplicasetServer `json:"master"`
+	// ActiveMaster is the instance currently serving writes
+	ActiveMaster *ReplicasetServer `json:"active_master"`
+}
+
+// ReplicasetServer .
+type ReplicasetServer struct {
+	UUID string `json:"uuid"`
 }
 
 // Server .
 type Server struct {
-	UUID    string `json:"uuid"`
-	Alias   string `json:"alias"`
-	URI     string `json:"uri"`
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	UUID       string            `json:"uuid"`
+	Alias      string            `json:"alias"`
+	URI        string            `json:"uri"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message"`
+	Replicaset *ServerReplicaset `json:"replicaset"`
+}
+
+// ServerReplicaset .
+type ServerReplicaset struct {
+	UUID string `json:"uuid"`
 }
 
 var log = logf.Log.WithName("topology")
 
+// errors backends return for outcomes the controller tolerates,
+// see IsAlreadyJoined and the like
 var (
-	errTopologyIsDown      = errors.New("topology service is down")
-	errAlreadyJoined       = errors.New("already joined")
-	errAlreadyBootstrapped = errors.New("already bootstrapped")
+	ErrTopologyIsDown      = errors.New("topology service is down")
+	ErrAlreadyJoined       = errors.New("already joined")
+	ErrAlreadyBootstrapped = errors.New("already bootstrapped")
+	ErrAlreadyExpelled     = errors.New("already expelled")
+	ErrMemtxNotSupported   = errors.New("set_memtx_memory mutation is not registered by the application")
 )
 
 var joinMutation = `mutation
@@ -154,21 +301,78 @@ var joinMutation = `mutation
 		$instance_uuid: String!,
 		$replicaset_uuid: String!,
 		$roles: [String!],
-		$vshard_group: String!
+		$vshard_group: String!,
+		$timeout: Float
 	) {
 	joinInstanceResponse: join_server(
 		uri: $uri,
 		instance_uuid: $instance_uuid,
 		replicaset_uuid: $replicaset_uuid,
 		roles: $roles,
-		timeout: 10,
+		timeout: $timeout,
 		vshard_group: $vshard_group
 	)
 }`
+
+// edit_topology applies all the changes in a single clusterwide config patch
+var editTopologyMutation = `mutation editTopology($replicasets: [EditReplicasetInput]) {
+	cluster {
+		edit_topology(replicasets: $replicasets) {
+			replicasets {
+				uuid
+			}
+		}
+	}
+}`
+
+var expelMutation = `mutation expelServer($uuid: String!) {
+	expel_instance: expel_server(uuid: $uuid)
+}`
+
+var bootstrapVshardMutation = `mutation bootstrap {
+	bootstrapVshardResponse: bootstrap_vshard
+}`
+
 var editRsMutation = `mutation editReplicaset($uuid: String!, $weight: Float) {
 	editReplicasetResponse: edit_replicaset(uuid: $uuid, weight: $weight)
 }`
 
+// set_memtx_memory is not a part of cartridge, the application registers
+// it to run box.cfg on the instance, see examples/kv
+var setMemtxMemoryMutation = `mutation setMemtxMemory($uuid: String!, $memtx_memory: Long!) {
+	setMemtxMemoryResponse: set_memtx_memory(uuid: $uuid, memtx_memory: $memtx_memory)
+}`
+
+var getMutationsQuery = `query mutations {
+	__schema {
+		mutationType {
+			fields {
+				name
+			}
+		}
+	}
+}`
+
+var getSelfQuery = `query self {
+	cluster {
+		self {
+			uri
+			uuid
+			alias
+			state
+		}
+	}
+}`
+
+var getKnownRolesQuery = `query knownRoles {
+	cluster {
+		known_roles {
+			name
+			dependencies
+		}
+	}
+}`
+
 var getServerStatQuery = `query serverList {
 	serverStat: servers {
 		uuid
@@ -191,6 +395,9 @@ var getReplicaSetListQuery = `query serverListWithoutStat {
 		uri
 		status
 		message
+		replicaset {
+			uuid
+		}
 	}
 	replicasetList: replicasets {
 		alias
@@ -200,13 +407,72 @@ var getReplicaSetListQuery = `query serverListWithoutStat {
 		roles
 		vshard_group
 		weight
+		master {
+			uuid
+		}
+		active_master {
+			uuid
+		}
 	}
 }`
 
-var statefulFailoverMutation = `mutation changeFailover($mode: String!, $state_provider: String, $etcd2_params: FailoverStateProviderCfgInputEtcd2, $tarantool_params: FailoverStateProviderCfgInputTarantool) {
+var editFailoverPriorityMutation = `mutation editFailoverPriority($uuid: String!, $failover_priority: [String!]) {
+	editReplicasetResponse: edit_replicaset(uuid: $uuid, failover_priority: $failover_priority)
+}`
+
+var failoverPromoteMutation = `mutation failoverPromote($replicaset_uuid: String!, $instance_uuid: String!) {
+	cluster {
+		failover_promote(replicaset_uuid: $replicaset_uuid, instance_uuid: $instance_uuid)
+	}
+}`
+
+var failoverParamsFields = `
+	mode
+	state_provider
+	failover_timeout
+	fencing_enabled
+	fencing_timeout
+	fencing_pause
+	tarantool_params {
+		uri
+		password
+	}
+	etcd2_params {
+		endpoints
+		prefix
+		lock_delay
+		username
+		password
+	}`
+
+var getFailoverParamsQuery = `query getFailoverParams {
+	cluster {
+		failover_params {` + failoverParamsFields + `
+		}
+	}
+}`
+
+var failoverParamsMutation = `mutation changeFailover(
+		$mode: String!,
+		$state_provider: String,
+		$failover_timeout: Float,
+		$fencing_enabled: Boolean,
+		$fencing_timeout: Float,
+		$fencing_pause: Float,
+		$etcd2_params: FailoverStateProviderCfgInputEtcd2,
+		$tarantool_params: FailoverStateProviderCfgInputTarantool
+	) {
 	cluster {
-		failover_params(mode: $mode, state_provider: $state_provider, etcd2_params: $etcd2_params, tarantool_params: $tarantool_params) {
-			mode
+		failover_params(
+			mode: $mode,
+			state_provider: $state_provider,
+			failover_timeout: $failover_timeout,
+			fencing_enabled: $fencing_enabled,
+			fencing_timeout: $fencing_timeout,
+			fencing_pause: $fencing_pause,
+			etcd2_params: $etcd2_params,
+			tarantool_params: $tarantool_params
+		) {` + failoverParamsFields + `
 		}
 	}
 }`
@@ -220,7 +486,7 @@ func GetRoles(pod *corev1.Pod) ([]string, error) {
 	if !ok {
 		rolesFromLabels, ok := thisPodLabels["tarantool.io/rolesToAssign"]
 		if !ok {
-			return nil, errors.New("role undefined")
+			return nil, invalidCall("role undefined")
 		}
 
 		roles := strings.Split(rolesFromLabels, ".")
@@ -244,26 +510,49 @@ func GetRoles(pod *corev1.Pod) ([]string, error) {
 		return roleArray, nil
 	}
 
-	return nil, errors.New("failed to parse roles from annotations")
+	return nil, invalidCall("failed to parse roles from annotations")
+}
+
+// GetVshardGroup gets vshard group of the pod, the default
+// one unless the pod uses vshard groups
+func GetVshardGroup(pod *corev1.Pod) (string, error) {
+	useVshardGroups, ok := pod.GetLabels()["tarantool.io/useVshardGroups"]
+	if !ok {
+		return "", invalidCall("failed to get label tarantool.io/useVshardGroups")
+	}
+
+	if useVshardGroups != "1" {
+		return "default", nil
+	}
+
+	vshardGroup, ok := pod.GetLabels()["tarantool.io/vshardGroupName"]
+	if !ok {
+		return "", invalidCall("vshard_group undefined")
+	}
+
+	return vshardGroup, nil
 }
 
 // Join comment
-func (s *BuiltInTopologyService) Join(pod *corev1.Pod) error {
+func (s *BuiltInTopologyService) Join(ctx context.Context, pod *corev1.Pod) error {
 
-	advURI := fmt.Sprintf("%s.%s.%s.svc.cluster.local:3301", pod.GetObjectMeta().GetName(), s.clusterID, pod.GetObjectMeta().GetNamespace())
+	if s.advertiseURI == nil {
+		return invalidCall("advertise uri is not configured")
+	}
+	advURI := s.advertiseURI(pod)
 
 	thisPodLabels := pod.GetLabels()
 
 	replicasetUUID, ok := thisPodLabels["tarantool.io/replicaset-uuid"]
 	if !ok {
-		return errors.New("replicaset uuid empty")
+		return invalidCall("replicaset uuid empty")
 	}
 
 	log.Info("payload", "advURI", advURI, "replicasetUUID", replicasetUUID)
 
 	instanceUUID, ok := thisPodLabels["tarantool.io/instance-uuid"]
 	if !ok {
-		return errors.New("instance uuid empty")
+		return invalidCall("instance uuid empty")
 	}
 
 	roles, err := GetRoles(pod)
@@ -272,161 +561,266 @@ func (s *BuiltInTopologyService) Join(pod *corev1.Pod) error {
 	}
 	log.Info("roles", "roles", roles)
 
-	vshardGroup := "default"
-	useVshardGroups, ok := thisPodLabels["tarantool.io/useVshardGroups"]
-	if !ok {
-		return errors.New("failed to get label tarantool.io/useVshardGroups")
+	vshardGroup, err := GetVshardGroup(pod)
+	if err != nil {
+		return err
 	}
 
-	if useVshardGroups == "1" {
-		vshardGroup, ok = thisPodLabels["tarantool.io/vshardGroupName"]
-		if !ok {
-			return errors.New("vshard_group undefined")
-		}
+	vars := map[string]interface{}{
+		"uri":             advURI,
+		"instance_uuid":   instanceUUID,
+		"replicaset_uuid": replicasetUUID,
+		"roles":           roles,
+		"vshard_group":    vshardGroup,
 	}
 
-	client := graphql.NewClient(s.serviceHost, graphql.WithHTTPClient(&http.Client{Timeout: time.Duration(time.Second * 5)}))
-	req := graphql.NewRequest(joinMutation)
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.Join)
+	defer cancel()
 
-	req.Var("uri", advURI)
-	req.Var("instance_uuid", instanceUUID)
-	req.Var("replicaset_uuid", replicasetUUID)
-	req.Var("roles", roles)
-	req.Var("vshard_group", vshardGroup)
+	// cartridge stops waiting for the instance before the call is
+	// abandoned, so that it does not keep configuring it unobserved
+	deadline, _ := ctx.Deadline()
+	vars["timeout"] = (time.Until(deadline) * 9 / 10).Seconds()
 
 	resp := &JoinResponseData{}
-	if err := client.Run(context.TODO(), req, resp); err != nil {
-
-		if strings.Contains(err.Error(), "already joined") {
-			return errAlreadyJoined
-		}
-		if strings.Contains(err.Error(), "This instance isn't bootstrapped yet") {
-			return errTopologyIsDown
-		}
-
+	if err := s.call(ctx, s.timeouts.Join, joinMutation, vars, resp); err != nil {
 		return err
 	}
 
-	if resp.JoinInstance == true {
-		return nil
+	if !resp.JoinInstance {
+		return unexpectedResponse("join_server")
 	}
 
-	return errors.New("something really bad happened")
+	return nil
 }
 
-// SetEventualFailover enables cluster failover
-func (s *BuiltInTopologyService) SetEventualFailover(enabled bool) error {
-	client := graphql.NewClient(s.serviceHost, graphql.WithHTTPClient(&http.Client{Timeout: time.Duration(time.Second * 5)}))
-	req := graphql.NewRequest(`mutation changeFailover($enabled: Boolean!) { cluster { failover(enabled: $enabled) }}`)
+// ApplyTopology creates replicasets, joins instances and edits replicasets
+// in one call, either all of the changes are applied or none
+func (s *BuiltInTopologyService) ApplyTopology(ctx context.Context, replicasets []*EditReplicasetInput) error {
+	reqLogger := log.WithValues("namespace", "topology.builtin")
+	reqLogger.Info("applying topology", "replicasets", len(replicasets))
 
-	req.Var("enabled", enabled)
+	resp := &EditTopologyData{}
+	if err := s.call(ctx, s.timeouts.EditTopology, editTopologyMutation, map[string]interface{}{"replicasets": replicasets}, resp); err != nil {
+		return err
+	}
 
-	resp := &FailoverData{}
-	if err := client.Run(context.TODO(), req, resp); err != nil {
-		log.Error(err, "failoverError")
-		return errors.New("failed to enable cluster failover")
+	if resp.Cluster == nil || resp.Cluster.EditTopology == nil {
+		return unexpectedResponse("edit_topology")
 	}
 
 	return nil
 }
 
-// SetTarantoolStatefulFailover .
-func (s *BuiltInTopologyService) SetTarantoolStatefulFailover(enabled bool, stateboardURI string, stateboardPassword string) error {
-	client := graphql.NewClient(s.serviceHost, graphql.WithHTTPClient(&http.Client{Timeout: time.Duration(time.Second * 5)}))
-	req := graphql.NewRequest(statefulFailoverMutation)
+// GetFailoverParams fetches failover configuration of the cluster
+func (s *BuiltInTopologyService) GetFailoverParams(ctx context.Context) (*FailoverParams, error) {
+	resp := &FailoverData{}
+	if err := s.call(ctx, s.timeouts.Call, getFailoverParamsQuery, nil, resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Cluster == nil || resp.Cluster.Failover == nil {
+		return nil, unexpectedResponse("failover_params")
+	}
 
-	req.Var("mode", "stateful")
-	req.Var("state_provider", "tarantool")
-	req.Var("etcd2_params", nil)
+	return resp.Cluster.Failover, nil
+}
 
-	conf := map[string]string{
-		"password": stateboardPassword,
-		"uri":      stateboardURI,
+// SetFailoverParams configures cluster failover, zero timeouts and
+// omitted state provider parameters are left unchanged
+func (s *BuiltInTopologyService) SetFailoverParams(ctx context.Context, params *FailoverParams) error {
+	vars := map[string]interface{}{
+		"mode":            params.Mode,
+		"fencing_enabled": params.FencingEnabled,
+	}
+	if params.StateProvider != "" {
+		vars["state_provider"] = params.StateProvider
+	}
+	if params.FailoverTimeout > 0 {
+		vars["failover_timeout"] = params.FailoverTimeout
+	}
+	if params.FencingTimeout > 0 {
+		vars["fencing_timeout"] = params.FencingTimeout
+	}
+	if params.FencingPause > 0 {
+		vars["fencing_pause"] = params.FencingPause
+	}
+	if params.TarantoolParams != nil {
+		vars["tarantool_params"] = params.TarantoolParams
+	}
+	if params.Etcd2Params != nil {
+		vars["etcd2_params"] = params.Etcd2Params
 	}
 
-	req.Var("tarantool_params", conf)
+	reqLogger := log.WithValues("namespace", "topology.builtin")
+	reqLogger.Info("setting failover params", "mode", params.Mode, "stateProvider", params.StateProvider)
 
 	resp := &FailoverData{}
-	if err := client.Run(context.TODO(), req, resp); err != nil {
+	if err := s.call(ctx, s.timeouts.Call, failoverParamsMutation, vars, resp); err != nil {
 		log.Error(err, "failoverError")
-		return errors.New("failed to enable tarantool stateful cluster failover")
+		return fmt.Errorf("failed to configure %s cluster failover: %w", params.Mode, err)
 	}
 
 	return nil
 }
 
-// SetConsulStatefulFailover .
-func (s *BuiltInTopologyService) SetConsulStatefulFailover(enabled bool, consulHost string, consulToken string) error {
+// Expel removes an instance from the replicaset
+func (s *BuiltInTopologyService) Expel(ctx context.Context, pod *corev1.Pod) error {
+	instanceUUID, ok := pod.GetLabels()["tarantool.io/instance-uuid"]
+	if !ok {
+		return invalidCall("instance uuid empty")
+	}
 
-	err := errors.New("SetConsulStatefulFailover is not yet implemented")
+	resp := &ExpelResponseData{}
+	if err := s.call(ctx, s.timeouts.Call, expelMutation, map[string]interface{}{"uuid": instanceUUID}, resp); err != nil {
+		return err
+	}
 
-	log.Error(err, "SetConsulStatefulFailover: this function is not yet implemented")
+	if !resp.ExpelInstance {
+		return unexpectedResponse("expel_server")
+	}
 
-	return err
+	return nil
 }
 
-// Expel removes an instance from the replicaset
-func (s *BuiltInTopologyService) Expel(pod *corev1.Pod) error {
-	req := fmt.Sprintf("mutation {expel_instance:expel_server(uuid:\\\"%s\\\")}", pod.GetAnnotations()["tarantool.io/instance_uuid"])
-	j := fmt.Sprintf("{\"query\": \"%s\"}", req)
-	rawResp, err := http.Post(s.serviceHost, "application/json", strings.NewReader(j))
+// SetWeight sets weight of a replicaset
+func (s *BuiltInTopologyService) SetWeight(ctx context.Context, replicasetUUID string, replicaWeight string) error {
+	reqLogger := log.WithValues("namespace", "topology.builtin")
+
+	weightParam, err := strconv.ParseUint(replicaWeight, 10, 32)
 	if err != nil {
-		return err
+		return invalidCall("invalid replicaset weight %q", replicaWeight)
 	}
-	defer rawResp.Body.Close()
 
-	resp := &ExpelResponse{Errors: []*ResponseError{}, Data: &ExpelResponseData{}}
-	if err := json.NewDecoder(rawResp.Body).Decode(resp); err != nil {
+	reqLogger.Info("setting cluster weight", "uuid", replicasetUUID, "weight", replicaWeight)
+
+	resp := &EditReplicasetResponse{}
+	if err := s.call(ctx, s.timeouts.Call, editRsMutation, map[string]interface{}{"uuid": replicasetUUID, "weight": weightParam}, resp); err != nil {
 		return err
 	}
 
-	if resp.Data.ExpelInstance == false && (resp.Errors == nil || len(resp.Errors) == 0) {
-		return errors.New("something really bad happened")
+	if !resp.Response {
+		return unexpectedResponse("edit_replicaset")
 	}
 
 	return nil
 }
 
-// SetWeight sets weight of a replicaset
-func (s *BuiltInTopologyService) SetWeight(replicasetUUID string, replicaWeight string) error {
-	client := graphql.NewClient(s.serviceHost, graphql.WithHTTPClient(&http.Client{Timeout: time.Duration(time.Second * 5)}))
-	req := graphql.NewRequest(editRsMutation)
+// Promote makes the instance a master of the replicaset: it is moved to the
+// top of the failover priority list and, with stateful failover, the state
+// provider is asked to appoint it right away
+func (s *BuiltInTopologyService) Promote(ctx context.Context, replicasetUUID string, instanceUUID string, stateful bool) error {
 
 	reqLogger := log.WithValues("namespace", "topology.builtin")
+	reqLogger.Info("promoting instance", "replicasetUUID", replicasetUUID, "instanceUUID", instanceUUID)
 
-	weightParam, err := strconv.ParseUint(replicaWeight, 10, 32)
-	if err != nil {
+	vars := map[string]interface{}{"uuid": replicasetUUID, "failover_priority": []string{instanceUUID}}
+
+	resp := &EditReplicasetResponse{}
+	if err := s.call(ctx, s.timeouts.Call, editFailoverPriorityMutation, vars, resp); err != nil {
 		return err
 	}
 
-	reqLogger.Info("setting cluster weight", "uuid", replicasetUUID, "weight", replicaWeight)
+	if !resp.Response {
+		return unexpectedResponse("edit_replicaset")
+	}
+
+	if !stateful {
+		return nil
+	}
 
-	req.Var("uuid", replicasetUUID)
-	req.Var("weight", weightParam)
+	vars = map[string]interface{}{"replicaset_uuid": replicasetUUID, "instance_uuid": instanceUUID}
+	if err := s.call(ctx, s.timeouts.Call, failoverPromoteMutation, vars, nil); err != nil {
+		return fmt.Errorf("failed to promote instance %s: %w", instanceUUID, err)
+	}
 
-	resp := &EditReplicasetResponse{}
-	if err := client.Run(context.TODO(), req, resp); err != nil {
+	return nil
+}
+
+// SetMemtxMemory grows memtx_memory of a running instance, tarantool does
+// not allow to shrink it without a restart. The admin API schema is checked
+// for the mutation first, ErrMemtxNotSupported tells it is not registered
+func (s *BuiltInTopologyService) SetMemtxMemory(ctx context.Context, instanceUUID string, memtxMemory int64) error {
+	reqLogger := log.WithValues("namespace", "topology.builtin")
+
+	if s.memtxSupported == nil {
+		schema := &SchemaData{}
+		if err := s.call(ctx, s.timeouts.Call, getMutationsQuery, nil, schema); err != nil {
+			return err
+		}
+		if schema.Schema == nil || schema.Schema.MutationType == nil {
+			return unexpectedResponse("__schema")
+		}
+
+		supported := false
+		for _, field := range schema.Schema.MutationType.Fields {
+			if field.Name == "set_memtx_memory" {
+				supported = true
+			}
+		}
+		s.memtxSupported = &supported
+	}
+	if !*s.memtxSupported {
+		return ErrMemtxNotSupported
+	}
+
+	reqLogger.Info("setting memtx_memory", "uuid", instanceUUID, "memtxMemory", memtxMemory)
+
+	resp := &SetMemtxMemoryResponse{}
+	if err := s.call(ctx, s.timeouts.Call, setMemtxMemoryMutation, map[string]interface{}{"uuid": instanceUUID, "memtx_memory": memtxMemory}, resp); err != nil {
 		return err
 	}
 
-	if resp.Response == true {
-		return nil
+	if !resp.Response {
+		return unexpectedResponse("set_memtx_memory")
 	}
 
-	return errors.New("something really bad happened")
+	return nil
 }
 
-// GetServerStat Fetch the replicaset as reported by cartridge
-func (s *BuiltInTopologyService) GetServerStat() (ServerStatData, error) {
-	client := graphql.NewClient(s.serviceHost, graphql.WithHTTPClient(&http.Client{Timeout: time.Duration(time.Second * 5)}))
-	req := graphql.NewRequest(getServerStatQuery)
+// GetSelf asks the instance serving admin API about itself, its uuid
+// is empty until the instance is joined to the cluster
+func (s *BuiltInTopologyService) GetSelf(ctx context.Context) (*Self, error) {
+	resp := &SelfData{}
+	if err := s.call(ctx, s.timeouts.Call, getSelfQuery, nil, resp); err != nil {
+		return nil, err
+	}
 
+	if resp.Cluster == nil || resp.Cluster.Self == nil {
+		return nil, unexpectedResponse("self")
+	}
+
+	return resp.Cluster.Self, nil
+}
+
+// GetKnownRoles lists roles the application provides
+func (s *BuiltInTopologyService) GetKnownRoles(ctx context.Context) ([]*KnownRole, error) {
+	resp := &KnownRolesData{}
+	if err := s.call(ctx, s.timeouts.Call, getKnownRolesQuery, nil, resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Cluster == nil {
+		return nil, unexpectedResponse("known_roles")
+	}
+
+	return resp.Cluster.KnownRoles, nil
+}
+
+// IsConfigured tells whether the instance is a part of the cluster
+// and has its roles configured
+func (s *Self) IsConfigured() bool {
+	return s.UUID != "" && s.State == "RolesConfigured"
+}
+
+// GetServerStat Fetch the replicaset as reported by cartridge
+func (s *BuiltInTopologyService) GetServerStat(ctx context.Context) (ServerStatData, error) {
 	reqLogger := log.WithValues("function", "GetServerStat")
 
 	reqLogger.Info("fetching server stats")
 
 	resp := ServerStatData{}
-	if err := client.Run(context.TODO(), req, &resp); err != nil {
+	if err := s.call(ctx, s.timeouts.Call, getServerStatQuery, nil, &resp); err != nil {
 		return resp, err
 	}
 
@@ -434,53 +828,27 @@ func (s *BuiltInTopologyService) GetServerStat() (ServerStatData, error) {
 }
 
 // BootstrapVshard enable the vshard service on the cluster
-func (s *BuiltInTopologyService) BootstrapVshard() error {
+func (s *BuiltInTopologyService) BootstrapVshard(ctx context.Context) error {
 	reqLogger := log.WithValues("namespace", "topology.builtin")
 
 	reqLogger.Info("Bootstrapping vshard")
 
-	req := fmt.Sprint("mutation bootstrap {bootstrapVshardResponse: bootstrap_vshard}")
-	j := fmt.Sprintf("{\"query\": \"%s\"}", req)
-	rawResp, err := http.Post(s.serviceHost, "application/json", strings.NewReader(j))
-	if err != nil {
-		return err
-	}
-
-	defer rawResp.Body.Close()
-
-	resp := &BootstrapVshardResponse{Data: &BootstrapVshardData{}}
-	if err := json.NewDecoder(rawResp.Body).Decode(resp); err != nil {
+	resp := &BootstrapVshardData{}
+	if err := s.call(ctx, s.timeouts.BootstrapVshard, bootstrapVshardMutation, nil, resp); err != nil {
 		return err
 	}
 
-	if resp.Data.BootstrapVshard {
-		return nil
-	}
-	if resp.Errors != nil && len(resp.Errors) > 0 {
-		if strings.Contains(resp.Errors[0].Message, "already bootstrapped") {
-			return errAlreadyBootstrapped
-		}
-
-		return errors.New(resp.Errors[0].Message)
+	if !resp.BootstrapVshard {
+		return unexpectedResponse("bootstrap_vshard")
 	}
 
-	return errors.New("unknown error")
+	return nil
 }
 
 // GetReplicaSetList .
-func (s *BuiltInTopologyService) GetReplicaSetList() (ReplicasetListResponse, error) {
+func (s *BuiltInTopologyService) GetReplicaSetList(ctx context.Context) (ReplicasetListResponse, error) {
 	resp := ReplicasetListResponse{}
-
-	req := fmt.Sprint(getReplicaSetListQuery)
-	j := fmt.Sprintf("{\"query\": \"%s\"}", req)
-	rawResp, err := http.Post(s.serviceHost, "application/json", strings.NewReader(j))
-	if err != nil {
-		return resp, err
-	}
-
-	defer rawResp.Body.Close()
-
-	if err := json.NewDecoder(rawResp.Body).Decode(&resp); err != nil {
+	if err := s.call(ctx, s.timeouts.Call, getReplicaSetListQuery, nil, &resp.Data); err != nil {
 		return resp, err
 	}
 
@@ -489,42 +857,74 @@ func (s *BuiltInTopologyService) GetReplicaSetList() (ReplicasetListResponse, er
 
 // IsTopologyDown .
 func IsTopologyDown(err error) bool {
-	return err == errTopologyIsDown
+	return errors.Is(err, ErrTopologyIsDown)
 }
 
 // IsAlreadyJoined .
 func IsAlreadyJoined(err error) bool {
-	return err == errAlreadyJoined
+	return errors.Is(err, ErrAlreadyJoined)
 }
 
 // IsAlreadyBootstrapped .
 func IsAlreadyBootstrapped(err error) bool {
-	return err == errAlreadyBootstrapped
+	return errors.Is(err, ErrAlreadyBootstrapped)
 }
 
-// Option .
-type Option func(s *BuiltInTopologyService)
+// IsAlreadyExpelled .
+func IsAlreadyExpelled(err error) bool {
+	return errors.Is(err, ErrAlreadyExpelled)
+}
 
-// WithTopologyEndpoint .
-func WithTopologyEndpoint(url string) Option {
-	return func(s *BuiltInTopologyService) {
-		s.serviceHost = url
-	}
+// IsMemtxNotSupported .
+func IsMemtxNotSupported(err error) bool {
+	return errors.Is(err, ErrMemtxNotSupported)
 }
 
-// WithClusterID .
-func WithClusterID(id string) Option {
-	return func(s *BuiltInTopologyService) {
-		s.clusterID = id
-	}
+// Failed returns the error of the first admin call which did not get a
+// response or got a server error, nil if there was none
+func (s *BuiltInTopologyService) Failed() error {
+	s.tracker.mu.Lock()
+	defer s.tracker.mu.Unlock()
+
+	return s.tracker.err
+}
+
+// CartridgeProvider is the backend managing tarantool cartridge
+// through the GraphQL admin API
+const CartridgeProvider = "cartridge"
+
+func init() {
+	Register(CartridgeProvider, func(config *Config) (TopologyService, error) {
+		return newBuiltInTopologyService(config), nil
+	})
 }
 
 // NewBuiltInTopologyService .
 func NewBuiltInTopologyService(opts ...Option) *BuiltInTopologyService {
-	s := &BuiltInTopologyService{}
-	for _, opt := range opts {
-		opt(s)
+	return newBuiltInTopologyService(NewConfig(opts...))
+}
+
+func newBuiltInTopologyService(config *Config) *BuiltInTopologyService {
+	s := &BuiltInTopologyService{
+		serviceHost:  config.Endpoint,
+		advertiseURI: config.AdvertiseURI,
+		httpClient:   config.HTTPClient,
+		timeouts:     config.Timeouts,
 	}
 
+	// clients built by hand may leave the transport to net/http
+	if s.httpClient == nil {
+		s.httpClient = defaultHTTPClient
+	}
+	transport := s.httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// admin calls share the connections of the configured client,
+	// failures are tracked per service
+	s.tracker = &failureTracker{transport: transport}
+	s.client = &http.Client{Transport: s.tracker, Timeout: s.httpClient.Timeout}
+
 	return s
 }
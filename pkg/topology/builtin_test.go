@@ -1,8 +1,15 @@
 package topology
 
 import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -149,3 +156,240 @@ func TestGetRoles_ParseRolesFromAnnotations(t *testing.T) {
 		}
 	}
 }
+
+func TestFailed(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"cluster": {"self": {"uri": "storage-0-0:3301", "uuid": "uuid", "state": "RolesConfigured"}}}}`))
+	}))
+	defer ok.Close()
+
+	s := NewBuiltInTopologyService(WithTopologyEndpoint(ok.URL))
+	self, err := s.GetSelf(context.Background())
+	if err != nil || !self.IsConfigured() {
+		t.Fatalf("expected configured instance, got %+v (%v)", self, err)
+	}
+	if s.Failed() != nil {
+		t.Fatalf("successful call must not fail the service, got %s", s.Failed())
+	}
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	s = NewBuiltInTopologyService(WithTopologyEndpoint(broken.URL))
+	if _, err := s.GetSelf(context.Background()); err == nil {
+		t.Fatalf("expected error from a broken instance")
+	}
+	if s.Failed() == nil {
+		t.Fatalf("server error must fail the service")
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	s := NewBuiltInTopologyService(WithTopologyEndpoint(slow.URL), WithTimeouts(Timeouts{Call: 50 * time.Millisecond}))
+	if _, err := s.GetSelf(context.Background()); err == nil {
+		t.Fatalf("expected call to time out")
+	}
+	if s.Failed() == nil {
+		t.Fatalf("timed out call must fail the service")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = NewBuiltInTopologyService(WithTopologyEndpoint(slow.URL))
+	if _, err := s.GetSelf(ctx); err == nil || !IsTransient(err) {
+		t.Fatalf("expected cancelled call to fail with a transient error, got %v", err)
+	}
+	if s.Failed() != nil {
+		t.Fatalf("cancelled call must not fail the service, got %s", s.Failed())
+	}
+
+	var joinTimeout float64
+	join := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &struct {
+			Variables struct {
+				Timeout float64 `json:"timeout"`
+			} `json:"variables"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		joinTimeout = req.Variables.Timeout
+		w.Write([]byte(`{"data": {"joinInstanceResponse": true}}`))
+	}))
+	defer join.Close()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "storage-0-0",
+			Labels: map[string]string{
+				"tarantool.io/replicaset-uuid": "rs-uuid",
+				"tarantool.io/instance-uuid":   "uuid",
+				"tarantool.io/rolesToAssign":   "storage",
+				"tarantool.io/useVshardGroups": "0",
+			},
+		},
+	}
+	s = NewBuiltInTopologyService(
+		WithTopologyEndpoint(join.URL),
+		WithAdvertiseURI(func(pod *corev1.Pod) string { return pod.GetName() + ":3301" }),
+		WithTimeouts(Timeouts{Join: 2 * time.Second}),
+	)
+	if err := s.Join(context.Background(), pod); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if joinTimeout <= 1 || joinTimeout >= 2 {
+		t.Fatalf("cartridge must wait for the instance less than the call lasts, got %v", joinTimeout)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "cookie" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data": {"cluster": {"self": {"uri": "storage-0-0:3301", "uuid": "uuid", "state": "RolesConfigured"}}}}`))
+	}))
+	defer server.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	cases := []struct {
+		config   *ClientConfig
+		expected bool
+	}{
+		{config: &ClientConfig{Username: "admin", Password: "cookie", CA: ca}, expected: true},
+		{config: &ClientConfig{Username: "admin", Password: "cookie", InsecureSkipVerify: true}, expected: true},
+		{config: &ClientConfig{Username: "admin", Password: "cookie"}, expected: false},
+		{config: &ClientConfig{CA: ca}, expected: false},
+	}
+
+	for i, c := range cases {
+		client, err := NewHTTPClient(c.config)
+		if err != nil {
+			t.Fatalf("%d: unexpected error %s", i, err.Error())
+		}
+
+		self, err := NewBuiltInTopologyService(WithTopologyEndpoint(server.URL), WithHTTPClient(client)).GetSelf(context.Background())
+		if c.expected && (err != nil || !self.IsConfigured()) {
+			t.Fatalf("%d: expected configured instance, got %+v (%v)", i, self, err)
+		}
+		if !c.expected && err == nil {
+			t.Fatalf("%d: expected the call to be rejected", i)
+		}
+	}
+
+	if _, err := NewHTTPClient(&ClientConfig{CA: []byte("garbage")}); err == nil {
+		t.Fatalf("expected error for a CA bundle without certificates")
+	}
+
+	// clients without a transport fall back to the ones of net/http
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"cluster": {"self": {"uri": "storage-0-0:3301", "uuid": "uuid", "state": "RolesConfigured"}}}}`))
+	}))
+	defer plain.Close()
+
+	for i, client := range []*http.Client{{}, nil} {
+		self, err := NewBuiltInTopologyService(WithTopologyEndpoint(plain.URL), WithHTTPClient(client)).GetSelf(context.Background())
+		if err != nil || !self.IsConfigured() {
+			t.Fatalf("%d: expected configured instance, got %+v (%v)", i, self, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		status    int
+		body      string
+		check     func(error) bool
+		transient bool
+		message   string
+	}{
+		{
+			status:  http.StatusOK,
+			body:    `{"errors": [{"message": "Server \"storage-0-0:3301\" is already joined", "extensions": {"io.tarantool.errors.class_name": "Editing cluster topology failed", "io.tarantool.errors.stack": "stack traceback:"}}]}`,
+			check:   IsAlreadyJoined,
+			message: "Editing cluster topology failed: Server \"storage-0-0:3301\" is already joined",
+		},
+		{
+			status:    http.StatusOK,
+			body:      `{"errors": [{"message": "Cluster isn't bootstrapped yet", "extensions": {"io.tarantool.errors.class_name": "PatchClusterwideError"}}]}`,
+			check:     IsTopologyDown,
+			transient: true,
+		},
+		{
+			status:    http.StatusOK,
+			body:      `{"errors": [{"message": "Connection refused", "extensions": {"io.tarantool.errors.class_name": "NetboxConnectError"}}]}`,
+			transient: true,
+		},
+		{
+			status: http.StatusOK,
+			body:   `{"errors": [{"message": "Cannot query field \"foo\" on type \"Query\""}]}`,
+		},
+		{
+			status: http.StatusOK,
+			body:   `{"data": {"expel_instance": false}}`,
+		},
+		{
+			status: http.StatusUnauthorized,
+			body:   `Unauthorized`,
+		},
+		{
+			status:    http.StatusServiceUnavailable,
+			transient: true,
+		},
+	}
+
+	for i, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			w.Write([]byte(c.body))
+		}))
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tarantool.io/instance-uuid": "uuid"}}}
+		err := NewBuiltInTopologyService(WithTopologyEndpoint(server.URL)).Expel(context.Background(), pod)
+		server.Close()
+
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%d: expected cartridge error, got %v", i, err)
+		}
+		if c.check != nil && !c.check(err) {
+			t.Fatalf("%d: error is classified wrong: %v", i, err)
+		}
+		if IsTransient(err) != c.transient || IsPermanent(err) == c.transient {
+			t.Fatalf("%d: expected transient %t, got %v", i, c.transient, err)
+		}
+		if c.message != "" && err.Error() != c.message {
+			t.Fatalf("%d: expected message %q, got %q", i, c.message, err.Error())
+		}
+	}
+
+	if !IsTransient(errors.New("connection refused")) {
+		t.Fatalf("errors which are not cartridge responses must be transient")
+	}
+
+	// calls refused before they are made fail the same way again
+	s := NewBuiltInTopologyService(WithTopologyEndpoint("http://127.0.0.1:0"))
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tarantool.io/replicaset-uuid": "rs-uuid"}}}
+	for _, err := range []error{
+		s.Join(context.Background(), pod),
+		s.Expel(context.Background(), pod),
+		s.SetWeight(context.Background(), "rs-uuid", "heavy"),
+		NewBuiltInTopologyService(WithTopologyEndpoint("http://127.0.0.1:0"), WithAdvertiseURI(func(*corev1.Pod) string { return "storage-0-0:3301" })).Join(context.Background(), pod),
+	} {
+		if !IsPermanent(err) {
+			t.Fatalf("expected refused call to fail with a permanent error, got %v", err)
+		}
+	}
+	if _, err := GetRoles(&corev1.Pod{}); !IsPermanent(err) {
+		t.Fatalf("expected undefined roles to be a permanent error, got %v", err)
+	}
+}
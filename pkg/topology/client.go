@@ -0,0 +1,93 @@
+package topology
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ClientConfig configures access to the cartridge admin API
+type ClientConfig struct {
+	// Username and Password enable HTTP basic auth, cartridge accepts
+	// the admin user with the cluster cookie as a password
+	Username string
+	Password string
+	// CA is a PEM bundle verifying instance certificates, system
+	// authorities are used when empty
+	CA []byte
+	// Cert and Key are a PEM client certificate and its key
+	Cert []byte
+	Key  []byte
+	// InsecureSkipVerify disables verification of instance certificates
+	InsecureSkipVerify bool
+}
+
+// defaultHTTPClient is used by services not given a client of their own
+var defaultHTTPClient, _ = NewHTTPClient(&ClientConfig{})
+
+// NewHTTPClient makes a client for admin API calls. Connections are
+// bounded by timeouts and kept alive, so the client is to be shared
+// by every call to the same cluster
+func NewHTTPClient(config *ClientConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+
+	if len(config.CA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CA) {
+			return nil, errors.New("no certificates found in CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.Cert) > 0 || len(config.Key) > 0 {
+		cert, err := tls.X509KeyPair(config.Cert, config.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	var transport http.RoundTripper = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   DefaultTimeouts.Call,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: DefaultTimeouts.Call,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	if config.Username != "" {
+		transport = &basicAuth{transport: transport, username: config.Username, password: config.Password}
+	}
+
+	// calls are bounded by the deadlines of their contexts
+	return &http.Client{Transport: transport}, nil
+}
+
+// basicAuth is a transport which authenticates every request
+type basicAuth struct {
+	transport http.RoundTripper
+	username  string
+	password  string
+}
+
+// RoundTrip .
+func (a *basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(a.username, a.password)
+
+	return a.transport.RoundTrip(req)
+}
+
+// CloseIdleConnections .
+func (a *basicAuth) CloseIdleConnections() {
+	if t, ok := a.transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
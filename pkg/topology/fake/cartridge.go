@@ -0,0 +1,439 @@
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+)
+
+// BucketCount is the number of vshard buckets bootstrap distributes
+const BucketCount = 3000
+
+// Server is an instance joined to the fake cluster
+type Server struct {
+	UUID           string
+	URI            string
+	Alias          string
+	ReplicasetUUID string
+	// Status is reported by the server list, healthy unless set otherwise
+	Status  string
+	Message string
+	// Buckets are vshard buckets stored by the instance
+	Buckets     int
+	MemtxMemory int64
+}
+
+// Replicaset is a replicaset of the fake cluster
+type Replicaset struct {
+	UUID  string
+	Alias string
+	// Roles are the ones assigned, cartridge reports them
+	// along with their dependencies
+	Roles       []string
+	AllRW       bool
+	Weight      float64
+	VshardGroup string
+	// Servers are instance uuids in the failover priority order
+	Servers []string
+	// ActiveMaster is the instance appointed by stateful failover,
+	// the first one of Servers is the master otherwise
+	ActiveMaster string
+}
+
+// Fault makes the fake fail calls matching it
+type Fault struct {
+	// Operation is the admin API field the call selects, fields of the
+	// cluster object are prefixed with cluster., e.g. expel_server or
+	// cluster.edit_topology, any if empty
+	Operation string
+	// Host limits the fault to calls served by the instance, any if empty
+	Host string
+	// Unreachable makes the instance refuse connections
+	Unreachable bool
+	// StatusCode makes the fake respond with an http error
+	StatusCode int
+	// Message and ClassName make the fake respond with a graphql error
+	Message   string
+	ClassName string
+	// Times is how many calls fail, every call if zero
+	Times int
+}
+
+// Cartridge is an in-process cartridge admin API keeping the cluster
+// topology in memory. Every instance is served by the same http server,
+// the one a call is addressed to is told by the host of the call
+type Cartridge struct {
+	mu     sync.Mutex
+	server *httptest.Server
+
+	servers     map[string]*Server
+	replicasets map[string]*Replicaset
+	failover    *topology.FailoverParams
+	// bootstrapped tells whether vshard is bootstrapped
+	bootstrapped bool
+	// rebalancerPaused keeps buckets where they are
+	rebalancerPaused bool
+	// memtxMutation tells whether the application registers set_memtx_memory
+	memtxMutation bool
+	// knownRoles are roles the application provides and their dependencies
+	knownRoles map[string][]string
+	faults     []*Fault
+	calls      []string
+	mutations  []string
+}
+
+// NewCartridge starts a fake admin API with an empty cluster
+func NewCartridge() *Cartridge {
+	c := &Cartridge{
+		servers:     make(map[string]*Server),
+		replicasets: make(map[string]*Replicaset),
+		failover:    &topology.FailoverParams{Mode: "disabled"},
+		knownRoles: map[string][]string{
+			"vshard-storage":       nil,
+			"vshard-router":        nil,
+			"failover-coordinator": nil,
+		},
+	}
+	c.server = httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+
+	return c
+}
+
+// Close shuts the fake down
+func (c *Cartridge) Close() {
+	c.server.Close()
+}
+
+// Client makes an http client which delivers calls to any instance
+// to the fake, use it with topology.WithHTTPClient
+func (c *Cartridge) Client() *http.Client {
+	return &http.Client{Transport: &redirect{cartridge: c, transport: http.DefaultTransport}}
+}
+
+// Inject adds a fault
+func (c *Cartridge) Inject(fault Fault) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.faults = append(c.faults, &fault)
+}
+
+// ClearFaults removes every fault
+func (c *Cartridge) ClearFaults() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.faults = nil
+}
+
+// Calls lists admin API fields served so far, named as Fault.Operation
+func (c *Cartridge) Calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string{}, c.calls...)
+}
+
+// Mutations lists admin API fields served so far by mutations
+func (c *Cartridge) Mutations() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string{}, c.mutations...)
+}
+
+// Servers lists joined instances sorted by URI
+func (c *Cartridge) Servers() []Server {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	servers := []Server{}
+	for _, s := range c.servers {
+		servers = append(servers, *s)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].URI < servers[j].URI })
+
+	return servers
+}
+
+// Replicasets lists replicasets sorted by alias
+func (c *Cartridge) Replicasets() []Replicaset {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	replicasets := []Replicaset{}
+	for _, rs := range c.replicasets {
+		copied := *rs
+		copied.Roles = append([]string{}, rs.Roles...)
+		copied.Servers = append([]string{}, rs.Servers...)
+		replicasets = append(replicasets, copied)
+	}
+	sort.Slice(replicasets, func(i, j int) bool { return replicasets[i].Alias < replicasets[j].Alias })
+
+	return replicasets
+}
+
+// Failover gets the cluster failover configuration
+func (c *Cartridge) Failover() topology.FailoverParams {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return *c.failover
+}
+
+// Bootstrapped tells whether vshard is bootstrapped
+func (c *Cartridge) Bootstrapped() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.bootstrapped
+}
+
+// SetServerStatus changes the status the instance is reported with
+func (c *Cartridge) SetServerStatus(uuid string, status string, message string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, ok := c.servers[uuid]
+	if !ok {
+		return fmt.Errorf("server %s is not joined", uuid)
+	}
+	server.Status = status
+	server.Message = message
+
+	return nil
+}
+
+// SetActiveMaster appoints the instance serving writes of its replicaset,
+// as stateful failover does
+func (c *Cartridge) SetActiveMaster(uuid string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, ok := c.servers[uuid]
+	if !ok {
+		return fmt.Errorf("server %s is not joined", uuid)
+	}
+	c.replicasets[server.ReplicasetUUID].ActiveMaster = uuid
+	c.rebalance()
+
+	return nil
+}
+
+// AddRole makes the application provide the role, dependencies
+// are enabled along with it and have to be known
+func (c *Cartridge) AddRole(name string, dependencies ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.knownRoles[name] = dependencies
+}
+
+// RegisterMemtxMutation adds set_memtx_memory to the admin API,
+// as applications growing memtx_memory at runtime do
+func (c *Cartridge) RegisterMemtxMutation() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.memtxMutation = true
+}
+
+// PauseRebalancer keeps buckets where they are until the rebalancer is resumed
+func (c *Cartridge) PauseRebalancer() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rebalancerPaused = true
+}
+
+// ResumeRebalancer moves buckets according to the current weights
+func (c *Cartridge) ResumeRebalancer() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rebalancerPaused = false
+	c.rebalance()
+}
+
+// fault finds the fault of the call and counts it
+func (c *Cartridge) fault(operation string, host string, unreachable bool) *Fault {
+	for i, f := range c.faults {
+		if f.Unreachable != unreachable {
+			continue
+		}
+		if f.Operation != "" && f.Operation != operation {
+			continue
+		}
+		if f.Host != "" && f.Host != host {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				c.faults = append(c.faults[:i], c.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+
+	return nil
+}
+
+// redirect is a transport delivering calls to the fake, the host
+// the call is addressed to is kept in the Host header
+type redirect struct {
+	cartridge *Cartridge
+	transport http.RoundTripper
+}
+
+// RoundTrip .
+func (t *redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.cartridge.mu.Lock()
+	fault := t.cartridge.fault("", req.URL.Hostname(), true)
+	t.cartridge.mu.Unlock()
+
+	if fault != nil {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+
+	redirected := req.Clone(req.Context())
+	redirected.URL.Scheme = "http"
+	redirected.URL.Host = t.cartridge.server.Listener.Addr().String()
+	redirected.Host = req.URL.Host
+
+	return t.transport.RoundTrip(redirected)
+}
+
+// hostname strips the port
+func hostname(uri string) string {
+	if host, _, err := net.SplitHostPort(uri); err == nil {
+		return host
+	}
+
+	return uri
+}
+
+// serverByHost finds the instance with the host, nil if it is not joined
+func (c *Cartridge) serverByHost(host string) *Server {
+	for _, s := range c.servers {
+		if hostname(s.URI) == host {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// isStorage tells whether the replicaset stores buckets
+func (c *Cartridge) isStorage(rs *Replicaset) bool {
+	for _, role := range c.enabledRoles(rs.Roles) {
+		if role == "vshard-storage" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// master gets the instance serving writes of the replicaset
+func (c *Cartridge) master(rs *Replicaset) *Server {
+	if rs.ActiveMaster != "" {
+		if s, ok := c.servers[rs.ActiveMaster]; ok {
+			return s
+		}
+	}
+	if len(rs.Servers) == 0 {
+		return nil
+	}
+
+	return c.servers[rs.Servers[0]]
+}
+
+// rebalance moves buckets to storage masters in proportion to replicaset
+// weights, as the vshard rebalancer eventually does
+func (c *Cartridge) rebalance() {
+	if !c.bootstrapped || c.rebalancerPaused {
+		return
+	}
+
+	storages := []*Replicaset{}
+	total := 0.0
+	for _, rs := range c.replicasets {
+		if c.isStorage(rs) && c.master(rs) != nil {
+			storages = append(storages, rs)
+			total += rs.Weight
+		}
+	}
+	sort.Slice(storages, func(i, j int) bool { return storages[i].UUID < storages[j].UUID })
+
+	for _, s := range c.servers {
+		s.Buckets = 0
+	}
+	if total == 0 {
+		return
+	}
+
+	// the remainder goes to the first replicaset which takes buckets
+	left := BucketCount
+	var first *Server
+	for _, rs := range storages {
+		buckets := int(float64(BucketCount) * rs.Weight / total)
+		c.master(rs).Buckets = buckets
+		left -= buckets
+		if first == nil && rs.Weight > 0 {
+			first = c.master(rs)
+		}
+	}
+	first.Buckets += left
+}
+
+// alias derives the instance alias from its URI, as the pod name
+func alias(uri string) string {
+	return strings.Split(hostname(uri), ".")[0]
+}
+
+// dependencies lists roles the role depends on, directly or not
+func (c *Cartridge) dependencies(role string) []string {
+	dependencies := []string{}
+	for _, dependency := range c.enabledRoles(c.knownRoles[role]) {
+		if dependency != role {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
+}
+
+// enabledRoles expands roles with their dependencies, sorted
+func (c *Cartridge) enabledRoles(roles []string) []string {
+	enabled := make(map[string]bool)
+	var enable func(role string)
+	enable = func(role string) {
+		if enabled[role] {
+			return
+		}
+		enabled[role] = true
+		for _, dependency := range c.knownRoles[role] {
+			enable(dependency)
+		}
+	}
+	for _, role := range roles {
+		enable(role)
+	}
+
+	result := []string{}
+	for role := range enabled {
+		result = append(result, role)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,99 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+)
+
+func TestCartridge(t *testing.T) {
+	c := NewCartridge()
+	defer c.Close()
+
+	ctx := context.Background()
+	s := topology.NewBuiltInTopologyService(
+		topology.WithTopologyEndpoint("http://storage-0-0.kv:8081/admin/api"),
+		topology.WithHTTPClient(c.Client()),
+	)
+
+	replicasets := []*topology.EditReplicasetInput{{
+		UUID:  "aaaaaaaa-0000-4000-b000-000000000000",
+		Alias: "storage-0",
+		Roles: []string{"vshard-storage"},
+		JoinServers: []*topology.JoinServerInput{
+			{URI: "storage-0-0.kv:3301", UUID: "aaaaaaaa-aaaa-4000-b000-000000000001"},
+			{URI: "storage-0-1.kv:3301", UUID: "aaaaaaaa-aaaa-4000-b000-000000000002"},
+		},
+	}}
+	if err := s.ApplyTopology(ctx, replicasets); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.BootstrapVshard(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	list, err := s.GetReplicaSetList(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list.Data.ReplicaSets) != 1 || len(list.Data.Servers) != 2 {
+		t.Fatalf("expected one replicaset of two servers, got %+v", list.Data)
+	}
+	if rs := list.Data.ReplicaSets[0]; rs.Alias != "storage-0" || rs.Master == nil || rs.Master.UUID != "aaaaaaaa-aaaa-4000-b000-000000000001" {
+		t.Fatalf("expected storage-0 led by its first server, got %+v", rs)
+	}
+
+	stat, err := s.GetServerStat(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	buckets := 0
+	for _, server := range stat.Stats {
+		buckets += server.Statistics.BucketsCount
+	}
+	if buckets != BucketCount {
+		t.Fatalf("expected %d buckets in server stats, got %d", BucketCount, buckets)
+	}
+
+	// calls are told by the fields they select, not by operation names
+	expected := []string{"cluster.edit_topology", "bootstrap_vshard"}
+	if mutations := c.Mutations(); len(mutations) != 2 || mutations[0] != expected[0] || mutations[1] != expected[1] {
+		t.Fatalf("expected %v mutations, got %v", expected, mutations)
+	}
+	if calls := c.Calls(); calls[len(calls)-1] != "servers" {
+		t.Fatalf("expected servers to be the last call, got %v", calls)
+	}
+
+	c.Inject(Fault{Operation: "cluster.edit_topology", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if err := s.ApplyTopology(ctx, nil); !topology.IsTransient(err) {
+		t.Fatalf("expected transient error, got %v", err)
+	}
+	if err := s.ApplyTopology(ctx, nil); err != nil {
+		t.Fatalf("expected fault to be cleared, got %s", err)
+	}
+
+	c.Inject(Fault{Operation: "cluster.failover_params", ClassName: "FailoverConfigError", Message: "Invalid failover mode"})
+	if _, err := s.GetFailoverParams(ctx); err == nil || err.Error() != "FailoverConfigError: Invalid failover mode" {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	c.ClearFaults()
+
+	body, _ := json.Marshal(map[string]string{"query": `query { foo }`})
+	resp, err := c.Client().Post("http://storage-0-0.kv:8081/admin/api", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+	result := struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Errors) != 1 || result.Errors[0].Message != `Cannot query field "foo" on type "Query"` {
+		t.Fatalf("expected unknown field error, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,63 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var registerScheme sync.Once
+
+// labelSelectingClient is the controller-runtime fake client which honors
+// label selectors of List calls, controllers rely on them to tell objects
+// of one cluster from another
+type labelSelectingClient struct {
+	client.Client
+}
+
+// NewClient makes a fake kubernetes client holding the objects, tarantool
+// resources are registered in the client-go scheme the fake decodes with
+func NewClient(objs ...runtime.Object) client.Client {
+	registerScheme.Do(func() {
+		if err := apis.AddToScheme(scheme.Scheme); err != nil {
+			panic(err)
+		}
+	})
+
+	return &labelSelectingClient{Client: fakeclient.NewFakeClient(objs...)}
+}
+
+// List .
+func (c *labelSelectingClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	if err := c.Client.List(ctx, opts, list); err != nil {
+		return err
+	}
+	if opts == nil || opts.LabelSelector == nil || opts.LabelSelector.Empty() {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	selected := []runtime.Object{}
+	for _, item := range items {
+		obj, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		if opts.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+			selected = append(selected, item)
+		}
+	}
+
+	return meta.SetList(list, selected)
+}
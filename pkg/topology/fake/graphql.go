@@ -0,0 +1,562 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+)
+
+// editTopologyClass is the class cartridge reports topology changes it rejects with
+const editTopologyClass = "Editing cluster topology failed"
+
+// request is a graphql call
+type request struct {
+	Query     string          `json:"query"`
+	Variables json.RawMessage `json:"variables"`
+}
+
+// responseError is a graphql error with cartridge extensions
+type responseError struct {
+	Message    string            `json:"message"`
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// callError is an error a handler fails the call with
+type callError struct {
+	className string
+	message   string
+}
+
+func (e *callError) Error() string {
+	return e.message
+}
+
+// statusError makes the fake respond with an http error
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+func newCallError(className string, format string, args ...interface{}) error {
+	return &callError{className: className, message: fmt.Sprintf(format, args...)}
+}
+
+// handler resolves a field of the admin API, arguments are taken from
+// the variables of the call
+type handler func(c *Cartridge, host string, vars json.RawMessage) (interface{}, error)
+
+// queries and mutations serve fields of the admin API by their paths,
+// fields of the cluster object are prefixed with cluster.
+var queries = map[string]handler{
+	"servers":                 (*Cartridge).getServers,
+	"replicasets":             (*Cartridge).getReplicasets,
+	"__schema":                (*Cartridge).schema,
+	"cluster.self":            (*Cartridge).self,
+	"cluster.known_roles":     (*Cartridge).getKnownRoles,
+	"cluster.failover_params": (*Cartridge).getFailoverParams,
+}
+
+var mutations = map[string]handler{
+	"join_server":              (*Cartridge).joinServer,
+	"expel_server":             (*Cartridge).expelServer,
+	"bootstrap_vshard":         (*Cartridge).bootstrapVshard,
+	"edit_replicaset":          (*Cartridge).editReplicaset,
+	"set_memtx_memory":         (*Cartridge).setMemtxMemory,
+	"cluster.edit_topology":    (*Cartridge).editTopology,
+	"cluster.failover_promote": (*Cartridge).failoverPromote,
+	"cluster.failover_params":  (*Cartridge).changeFailover,
+}
+
+func (c *Cartridge) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	req := &request{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	doc, err := parseQuery(req.Query)
+	if err != nil {
+		writeResponse(w, nil, err)
+		return
+	}
+	host := hostname(r.Host)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := make(map[string]interface{}, len(doc.fields))
+	for _, f := range doc.fields {
+		value, err := c.resolve(doc, "", f, host, req.Variables)
+		if e, ok := err.(*statusError); ok {
+			w.WriteHeader(e.code)
+			return
+		}
+		if err != nil {
+			writeResponse(w, nil, err)
+			return
+		}
+		data[f.key()] = project(value, f.fields)
+	}
+
+	writeResponse(w, data, nil)
+}
+
+// resolve serves a field of the call, the cluster object is resolved
+// field by field
+func (c *Cartridge) resolve(doc *document, prefix string, f *field, host string, vars json.RawMessage) (interface{}, error) {
+	path := prefix + f.name
+	if path == "cluster" {
+		cluster := make(map[string]interface{}, len(f.fields))
+		for _, sub := range f.fields {
+			value, err := c.resolve(doc, "cluster.", sub, host, vars)
+			if err != nil {
+				return nil, err
+			}
+			cluster[sub.name] = value
+		}
+		return cluster, nil
+	}
+
+	handlers, typeName := queries, "Query"
+	if doc.mutation {
+		handlers, typeName = mutations, "Mutation"
+	}
+	h, ok := handlers[path]
+	if !ok {
+		return nil, fmt.Errorf("Cannot query field %q on type %q", f.name, typeName)
+	}
+
+	c.calls = append(c.calls, path)
+	if doc.mutation {
+		c.mutations = append(c.mutations, path)
+	}
+
+	if fault := c.fault(path, host, false); fault != nil {
+		if fault.StatusCode != 0 {
+			return nil, &statusError{code: fault.StatusCode}
+		}
+		return nil, &callError{className: fault.ClassName, message: fault.Message}
+	}
+
+	value, err := h(c, host, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	// values are responded in the shape cartridge gives them, so that
+	// the fields the call selects are picked by their names
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var resolved interface{}
+	if err := json.Unmarshal(raw, &resolved); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+func writeResponse(w http.ResponseWriter, data interface{}, err error) {
+	resp := map[string]interface{}{"data": data}
+	if err != nil {
+		respErr := &responseError{Message: err.Error()}
+		if e, ok := err.(*callError); ok && e.className != "" {
+			respErr.Extensions = map[string]string{
+				"io.tarantool.errors.class_name": e.className,
+				"io.tarantool.errors.stack":      "stack traceback:\n\t[C]: in function 'error'",
+			}
+		}
+		resp = map[string]interface{}{"data": nil, "errors": []*responseError{respErr}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (c *Cartridge) self(host string, vars json.RawMessage) (interface{}, error) {
+	self := &topology.Self{URI: host, State: "Unconfigured"}
+	if s := c.serverByHost(host); s != nil {
+		self = &topology.Self{URI: s.URI, UUID: s.UUID, Alias: s.Alias, State: "RolesConfigured"}
+	}
+
+	return self, nil
+}
+
+func (c *Cartridge) getKnownRoles(host string, vars json.RawMessage) (interface{}, error) {
+	roles := []*topology.KnownRole{}
+	for name := range c.knownRoles {
+		roles = append(roles, &topology.KnownRole{Name: name, Dependencies: c.dependencies(name)})
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	return roles, nil
+}
+
+func (c *Cartridge) getServers(host string, vars json.RawMessage) (interface{}, error) {
+	servers := []map[string]interface{}{}
+	for _, s := range c.servers {
+		servers = append(servers, map[string]interface{}{
+			"uuid":       s.UUID,
+			"alias":      s.Alias,
+			"uri":        s.URI,
+			"status":     s.Status,
+			"message":    s.Message,
+			"replicaset": map[string]string{"uuid": s.ReplicasetUUID},
+			"statistics": map[string]interface{}{
+				"quota_size":           s.MemtxMemory,
+				"arena_used":           0,
+				"vshard_buckets_count": s.Buckets,
+				"quota_used_ratio":     "0.00%",
+				"arena_used_ratio":     "0.00%",
+				"items_used_ratio":     "0.00%",
+			},
+		})
+	}
+
+	return servers, nil
+}
+
+func (c *Cartridge) getReplicasets(host string, vars json.RawMessage) (interface{}, error) {
+	replicasets := []*topology.ReplicaSet{}
+	for _, rs := range c.replicasets {
+		replicaset := &topology.ReplicaSet{
+			UUID:        rs.UUID,
+			Alias:       rs.Alias,
+			Roles:       c.enabledRoles(rs.Roles),
+			AllRW:       rs.AllRW,
+			Weight:      rs.Weight,
+			VshardGroup: rs.VshardGroup,
+			Status:      "healthy",
+		}
+		if len(rs.Servers) > 0 {
+			replicaset.Master = &topology.ReplicasetServer{UUID: rs.Servers[0]}
+		}
+		if master := c.master(rs); master != nil {
+			replicaset.ActiveMaster = &topology.ReplicasetServer{UUID: master.UUID}
+		}
+		replicasets = append(replicasets, replicaset)
+	}
+
+	return replicasets, nil
+}
+
+func (c *Cartridge) editTopology(host string, vars json.RawMessage) (interface{}, error) {
+	input := struct {
+		Replicasets []*topology.EditReplicasetInput `json:"replicasets"`
+	}{}
+	if err := json.Unmarshal(vars, &input); err != nil {
+		return nil, err
+	}
+
+	// the first call forms the cluster and has to be served by one of
+	// the instances it joins
+	if len(c.servers) == 0 {
+		serving := false
+		for _, edit := range input.Replicasets {
+			for _, join := range edit.JoinServers {
+				serving = serving || hostname(join.URI) == host
+			}
+		}
+		if !serving {
+			return nil, newCallError("PatchClusterwideError", "Cluster isn't bootstrapped yet")
+		}
+	}
+
+	// validate everything first, the change is applied either whole or not at all
+	joining := make(map[string]bool)
+	for _, edit := range input.Replicasets {
+		rs, exists := c.replicasets[edit.UUID]
+		if !exists && len(edit.JoinServers) == 0 {
+			return nil, newCallError(editTopologyClass, "Replicaset %q does not exist", edit.UUID)
+		}
+		for _, join := range edit.JoinServers {
+			if _, ok := c.servers[join.UUID]; ok || joining[join.UUID] {
+				return nil, newCallError(editTopologyClass, "Server %q is already joined", join.URI)
+			}
+			joining[join.UUID] = true
+		}
+		if exists && edit.VshardGroup != "" && rs.VshardGroup != "" && edit.VshardGroup != rs.VshardGroup && c.isStorage(rs) {
+			return nil, newCallError("Invalid cluster topology config", "replicasets[%s].vshard_group can't be modified", edit.UUID)
+		}
+		for _, role := range edit.Roles {
+			if _, ok := c.knownRoles[role]; !ok {
+				return nil, newCallError("Invalid cluster topology config", "replicasets[%s] can not enable unknown role %q", edit.UUID, role)
+			}
+		}
+	}
+
+	result := []map[string]string{}
+	for _, edit := range input.Replicasets {
+		rs, exists := c.replicasets[edit.UUID]
+		if !exists {
+			rs = &Replicaset{UUID: edit.UUID, Alias: "unnamed", Weight: 0}
+			c.replicasets[edit.UUID] = rs
+		}
+
+		if edit.Alias != "" {
+			rs.Alias = edit.Alias
+		}
+		if edit.Roles != nil {
+			rs.Roles = append([]string{}, edit.Roles...)
+		}
+		if edit.AllRW != nil {
+			rs.AllRW = *edit.AllRW
+		}
+		if edit.VshardGroup != "" {
+			rs.VshardGroup = edit.VshardGroup
+		}
+		if edit.Weight != nil {
+			rs.Weight = *edit.Weight
+		} else if !exists && c.isStorage(rs) && !c.bootstrapped {
+			rs.Weight = 1
+		}
+
+		for _, join := range edit.JoinServers {
+			c.servers[join.UUID] = &Server{
+				UUID:           join.UUID,
+				URI:            join.URI,
+				Alias:          alias(join.URI),
+				ReplicasetUUID: rs.UUID,
+				Status:         "healthy",
+			}
+			rs.Servers = append(rs.Servers, join.UUID)
+		}
+
+		result = append(result, map[string]string{"uuid": rs.UUID})
+	}
+
+	c.rebalance()
+
+	return map[string]interface{}{"replicasets": result}, nil
+}
+
+func (c *Cartridge) joinServer(host string, vars json.RawMessage) (interface{}, error) {
+	input := struct {
+		URI            string   `json:"uri"`
+		InstanceUUID   string   `json:"instance_uuid"`
+		ReplicasetUUID string   `json:"replicaset_uuid"`
+		Roles          []string `json:"roles"`
+		VshardGroup    string   `json:"vshard_group"`
+	}{}
+	if err := json.Unmarshal(vars, &input); err != nil {
+		return nil, err
+	}
+
+	edit := &topology.EditReplicasetInput{
+		UUID:        input.ReplicasetUUID,
+		JoinServers: []*topology.JoinServerInput{{URI: input.URI, UUID: input.InstanceUUID}},
+	}
+	if _, ok := c.replicasets[input.ReplicasetUUID]; !ok {
+		edit.Roles = input.Roles
+		edit.VshardGroup = input.VshardGroup
+	}
+
+	editVars, _ := json.Marshal(map[string]interface{}{"replicasets": []*topology.EditReplicasetInput{edit}})
+	if _, err := c.editTopology(host, editVars); err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+func (c *Cartridge) expelServer(host string, vars json.RawMessage) (interface{}, error) {
+	input := struct {
+		UUID string `json:"uuid"`
+	}{}
+	if err := json.Unmarshal(vars, &input); err != nil {
+		return nil, err
+	}
+
+	server, ok := c.servers[input.UUID]
+	if !ok {
+		return nil, newCallError(editTopologyClass, "Server %q not in clusterwide config", input.UUID)
+	}
+
+	rs := c.replicasets[server.ReplicasetUUID]
+	if rs != nil && c.isStorage(rs) && server.Buckets > 0 {
+		return nil, newCallError("Invalid cluster topology config", "Server %q has vshard buckets", server.URI)
+	}
+	if rs != nil && len(rs.Servers) > 1 && c.master(rs) == server {
+		return nil, newCallError("Invalid cluster topology config", "Server %q is the leader and can't be expelled", server.URI)
+	}
+
+	delete(c.servers, input.UUID)
+	if rs != nil {
+		servers := []string{}
+		for _, uuid := range rs.Servers {
+			if uuid != input.UUID {
+				servers = append(servers, uuid)
+			}
+		}
+		rs.Servers = servers
+		if rs.ActiveMaster == input.UUID {
+			rs.ActiveMaster = ""
+		}
+		if len(rs.Servers) == 0 {
+			delete(c.replicasets, rs.UUID)
+		}
+	}
+
+	return true, nil
+}
+
+func (c *Cartridge) bootstrapVshard(host string, vars json.RawMessage) (interface{}, error) {
+	if c.bootstrapped {
+		return nil, newCallError("BootstrapError", "Sharding is already bootstrapped")
+	}
+
+	storages := false
+	for _, rs := range c.replicasets {
+		storages = storages || c.isStorage(rs)
+	}
+	if !storages {
+		return nil, newCallError("BootstrapError", "Sharding config is empty")
+	}
+
+	c.bootstrapped = true
+	c.rebalance()
+
+	return true, nil
+}
+
+func (c *Cartridge) editReplicaset(host string, vars json.RawMessage) (interface{}, error) {
+	input := struct {
+		UUID             string   `json:"uuid"`
+		Weight           *float64 `json:"weight"`
+		FailoverPriority []string `json:"failover_priority"`
+	}{}
+	if err := json.Unmarshal(vars, &input); err != nil {
+		return nil, err
+	}
+
+	rs, ok := c.replicasets[input.UUID]
+	if !ok {
+		return nil, newCallError(editTopologyClass, "Replicaset %q does not exist", input.UUID)
+	}
+
+	if input.FailoverPriority != nil {
+		priority := []string{}
+		listed := make(map[string]bool)
+		for _, uuid := range input.FailoverPriority {
+			if s, ok := c.servers[uuid]; !ok || s.ReplicasetUUID != rs.UUID {
+				return nil, newCallError("Invalid cluster topology config", "replicasets[%s].failover_priority %q is not in the replicaset", rs.UUID, uuid)
+			}
+			priority = append(priority, uuid)
+			listed[uuid] = true
+		}
+		for _, uuid := range rs.Servers {
+			if !listed[uuid] {
+				priority = append(priority, uuid)
+			}
+		}
+		rs.Servers = priority
+		if c.failover.Mode != "stateful" {
+			rs.ActiveMaster = ""
+		}
+	}
+	if input.Weight != nil {
+		rs.Weight = *input.Weight
+	}
+	c.rebalance()
+
+	return true, nil
+}
+
+func (c *Cartridge) failoverPromote(host string, vars json.RawMessage) (interface{}, error) {
+	input := struct {
+		ReplicasetUUID string `json:"replicaset_uuid"`
+		InstanceUUID   string `json:"instance_uuid"`
+	}{}
+	if err := json.Unmarshal(vars, &input); err != nil {
+		return nil, err
+	}
+
+	if c.failover.Mode != "stateful" {
+		return nil, newCallError("AppointmentError", "Promotion only works with stateful failover")
+	}
+
+	rs, ok := c.replicasets[input.ReplicasetUUID]
+	if !ok {
+		return nil, newCallError("AppointmentError", "Replicaset %q does not exist", input.ReplicasetUUID)
+	}
+	if s, ok := c.servers[input.InstanceUUID]; !ok || s.ReplicasetUUID != rs.UUID {
+		return nil, newCallError("AppointmentError", "Server %q is not in replicaset %q", input.InstanceUUID, rs.UUID)
+	}
+
+	rs.ActiveMaster = input.InstanceUUID
+	c.rebalance()
+
+	return true, nil
+}
+
+func (c *Cartridge) setMemtxMemory(host string, vars json.RawMessage) (interface{}, error) {
+	if !c.memtxMutation {
+		return nil, fmt.Errorf("Cannot query field \"set_memtx_memory\" on type \"Mutation\"")
+	}
+
+	input := struct {
+		UUID        string `json:"uuid"`
+		MemtxMemory int64  `json:"memtx_memory"`
+	}{}
+	if err := json.Unmarshal(vars, &input); err != nil {
+		return nil, err
+	}
+
+	server, ok := c.servers[input.UUID]
+	if !ok {
+		return nil, fmt.Errorf("Server %q not found", input.UUID)
+	}
+	if input.MemtxMemory < server.MemtxMemory {
+		return nil, fmt.Errorf("cannot decrease memory size at runtime")
+	}
+	server.MemtxMemory = input.MemtxMemory
+
+	return true, nil
+}
+
+func (c *Cartridge) getFailoverParams(host string, vars json.RawMessage) (interface{}, error) {
+	return c.failover, nil
+}
+
+func (c *Cartridge) changeFailover(host string, vars json.RawMessage) (interface{}, error) {
+	params := *c.failover
+	if err := json.Unmarshal(vars, &params); err != nil {
+		return nil, err
+	}
+
+	if params.Mode == "stateful" {
+		switch {
+		case params.StateProvider == "tarantool" && params.TarantoolParams == nil,
+			params.StateProvider == "etcd2" && params.Etcd2Params == nil:
+			return nil, newCallError("FailoverConfigError", "%s state provider is not configured", params.StateProvider)
+		case params.StateProvider != "tarantool" && params.StateProvider != "etcd2":
+			return nil, newCallError("FailoverConfigError", "Unknown failover state provider %q", params.StateProvider)
+		}
+	}
+
+	c.failover = &params
+
+	return c.failover, nil
+}
+
+// schema introspects mutations the admin API serves
+func (c *Cartridge) schema(host string, vars json.RawMessage) (interface{}, error) {
+	names := []string{"join_server", "expel_server", "edit_server", "edit_replicaset", "bootstrap_vshard", "cluster"}
+	if c.memtxMutation {
+		names = append(names, "set_memtx_memory")
+	}
+
+	fields := []*topology.SchemaField{}
+	for _, name := range names {
+		fields = append(fields, &topology.SchemaField{Name: name})
+	}
+
+	return &topology.Schema{MutationType: &topology.SchemaType{Fields: fields}}, nil
+}
@@ -0,0 +1,155 @@
+package fake
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// token matches graphql names, punctuators, strings and numbers,
+// whitespace and commas are insignificant
+var token = regexp.MustCompile(`[_A-Za-z][_0-9A-Za-z]*|[$!=:@(){}\[\]]|"(?:[^"\\]|\\.)*"|-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?`)
+
+// field is a field of a selection set, arguments are not kept as
+// the admin API client passes them in variables
+type field struct {
+	alias  string
+	name   string
+	fields []*field
+}
+
+// key is the name the field is responded under
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+
+	return f.name
+}
+
+// document is a parsed graphql operation
+type document struct {
+	mutation bool
+	fields   []*field
+}
+
+// parseQuery parses the single operation of a graphql call
+func parseQuery(query string) (*document, error) {
+	p := &parser{tokens: token.FindAllString(query, -1)}
+	doc := &document{}
+
+	switch p.peek() {
+	case "mutation":
+		doc.mutation = true
+		p.next()
+	case "query":
+		p.next()
+	}
+	if p.peek() != "{" && p.peek() != "(" {
+		p.next()
+	}
+	if p.peek() == "(" {
+		p.skipArguments()
+	}
+
+	fields, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	doc.fields = fields
+
+	return doc, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+
+	return t
+}
+
+// skipArguments skips variable definitions or field arguments
+func (p *parser) skipArguments() {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		switch p.next() {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *parser) selectionSet() ([]*field, error) {
+	if t := p.next(); t != "{" {
+		return nil, fmt.Errorf("Syntax error: expected {, got %q", t)
+	}
+
+	fields := []*field{}
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, fmt.Errorf("Syntax error: unexpected end of the query")
+		}
+
+		f := &field{name: p.next()}
+		if p.peek() == ":" {
+			p.next()
+			f.alias, f.name = f.name, p.next()
+		}
+		if p.peek() == "(" {
+			p.skipArguments()
+		}
+		if p.peek() == "{" {
+			selection, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			f.fields = selection
+		}
+		fields = append(fields, f)
+	}
+	p.next()
+
+	return fields, nil
+}
+
+// project picks the fields the call selected from a resolved value
+// and responds them under their aliases
+func project(value interface{}, fields []*field) interface{} {
+	if len(fields) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			out[f.key()] = project(v[f.name], f.fields)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			out = append(out, project(item, fields))
+		}
+		return out
+	}
+
+	return value
+}
@@ -0,0 +1,48 @@
+package fake
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	doc, err := parseQuery(`mutation
+	do_join_server(
+		$uri: String!,
+		$roles: [String!]
+	) {
+	joinInstanceResponse: join_server(uri: $uri, roles: $roles)
+}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !doc.mutation || len(doc.fields) != 1 || doc.fields[0].name != "join_server" || doc.fields[0].key() != "joinInstanceResponse" {
+		t.Fatalf("expected aliased join_server mutation, got %+v", doc.fields)
+	}
+
+	doc, err = parseQuery(`query {
+	cluster {
+		self { uri uuid }
+	}
+	replicasetList: replicasets { uuid master { uuid } }
+}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if doc.mutation || len(doc.fields) != 2 {
+		t.Fatalf("expected query of two fields, got %+v", doc.fields)
+	}
+	if cluster := doc.fields[0]; cluster.name != "cluster" || len(cluster.fields) != 1 || cluster.fields[0].name != "self" {
+		t.Fatalf("expected cluster.self, got %+v", cluster)
+	}
+
+	value := []interface{}{map[string]interface{}{"uuid": "rs", "alias": "storage-0", "master": map[string]interface{}{"uuid": "s", "uri": "storage-0-0:3301"}}}
+	expected := []interface{}{map[string]interface{}{"uuid": "rs", "master": map[string]interface{}{"uuid": "s"}}}
+	if projected := project(value, doc.fields[1].fields); !reflect.DeepEqual(projected, expected) {
+		t.Fatalf("expected selected fields only, got %v", projected)
+	}
+
+	if _, err := parseQuery(`query { servers { uuid }`); err == nil {
+		t.Fatalf("expected error for an unterminated selection set")
+	}
+}
@@ -0,0 +1,203 @@
+package topology
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// graphqlRequest is an admin API call
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphqlResponse is an admin API response, data is decoded once the
+// response is known to have no errors
+type graphqlResponse struct {
+	Data   json.RawMessage  `json:"data"`
+	Errors []*ResponseError `json:"errors,omitempty"`
+}
+
+// ResponseError is a graphql error as cartridge reports it
+type ResponseError struct {
+	Message    string           `json:"message"`
+	Extensions *ErrorExtensions `json:"extensions,omitempty"`
+}
+
+// ErrorExtensions is the error class and the stack cartridge
+// attaches to errors raised by its handlers
+type ErrorExtensions struct {
+	ClassName string `json:"io.tarantool.errors.class_name"`
+	Stack     string `json:"io.tarantool.errors.stack"`
+}
+
+// Error is an error cartridge responded with, or the one of a call
+// refused before it is made
+type Error struct {
+	// ClassName is the cartridge error class, empty for errors
+	// raised outside of cartridge handlers, e.g. by graphql validation
+	ClassName string
+	Message   string
+	Stack     string
+	// StatusCode is set when the admin API responded with an http error
+	StatusCode int
+
+	kind      error
+	transient bool
+}
+
+// Error .
+func (e *Error) Error() string {
+	if e.ClassName != "" {
+		return fmt.Sprintf("%s: %s", e.ClassName, e.Message)
+	}
+
+	return e.Message
+}
+
+// Unwrap gives the kind the error is classified as, see IsAlreadyJoined and the like
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// Transient tells whether the call may succeed if it is made again later
+func (e *Error) Transient() bool {
+	return e.transient
+}
+
+// errorKinds classifies cartridge errors by class, the first matching entry
+// wins. A few classes are raised for several reasons, for them the message
+// is matched too, entries without one match any message of the class.
+// Errors of other classes are permanent, as cartridge rejected the call
+// it was given
+var errorKinds = []struct {
+	className string
+	message   string
+	kind      error
+	transient bool
+}{
+	// edit_topology and join_server change the clusterwide config
+	// with patch_clusterwide
+	{className: "PatchClusterwideError", message: "isn't bootstrapped yet", kind: ErrTopologyIsDown, transient: true},
+	// another clusterwide config change is being applied
+	{className: "PatchClusterwideError", message: "is already running", transient: true},
+	{className: "Editing cluster topology failed", message: "is already joined", kind: ErrAlreadyJoined},
+	{className: "Editing cluster topology failed", message: "not in clusterwide config", kind: ErrAlreadyExpelled},
+	{className: "BootstrapError", message: "already bootstrapped", kind: ErrAlreadyBootstrapped},
+	{className: "Prepare2pcError", transient: true},
+	{className: "Commit2pcError", transient: true},
+	// the instance serving the call failed to reach other instances
+	{className: "NetboxConnectError", transient: true},
+	{className: "NetboxCallError", transient: true},
+}
+
+// newError classifies a graphql error cartridge responded with
+func newError(respErr *ResponseError) *Error {
+	e := &Error{Message: respErr.Message}
+	if respErr.Extensions != nil {
+		e.ClassName = respErr.Extensions.ClassName
+		e.Stack = respErr.Extensions.Stack
+	}
+
+	for _, k := range errorKinds {
+		if k.className != e.ClassName {
+			continue
+		}
+		if k.message != "" && !strings.Contains(e.Message, k.message) {
+			continue
+		}
+
+		e.kind = k.kind
+		e.transient = k.transient
+		break
+	}
+
+	return e
+}
+
+// IsTransient tells whether the failed call may succeed if it is made again:
+// the admin API was not reached, failed itself or cartridge is busy. Errors
+// which are not cartridge responses come from the transport and are transient
+func IsTransient(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.transient
+	}
+
+	return err != nil
+}
+
+// IsPermanent tells whether cartridge rejected the call, so that it is
+// bound to fail again until the cluster or the call changes
+func IsPermanent(err error) bool {
+	return err != nil && !IsTransient(err)
+}
+
+// call makes an admin API call bounded by the timeout and the context and
+// decodes response data into resp. Errors cartridge responds with are
+// returned as *Error
+func (s *BuiltInTopologyService) call(ctx context.Context, timeout time.Duration, query string, vars map[string]interface{}, resp interface{}) error {
+	body, err := json.Marshal(&graphqlRequest{Query: query, Variables: vars})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, s.serviceHost, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	rawResp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rawResp.Body.Close()
+
+	gqlResp := &graphqlResponse{}
+	decodeErr := json.NewDecoder(rawResp.Body).Decode(gqlResp)
+
+	if len(gqlResp.Errors) > 0 {
+		return newError(gqlResp.Errors[0])
+	}
+
+	if rawResp.StatusCode != http.StatusOK {
+		return &Error{
+			Message:    fmt.Sprintf("admin api responded with %s", rawResp.Status),
+			StatusCode: rawResp.StatusCode,
+			transient:  rawResp.StatusCode >= http.StatusInternalServerError || rawResp.StatusCode == http.StatusTooManyRequests,
+		}
+	}
+
+	if decodeErr != nil {
+		return decodeErr
+	}
+
+	if resp == nil || len(gqlResp.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(gqlResp.Data, resp)
+}
+
+// invalidCall is the error of a call which is not made as the pod or the
+// arguments lack what cartridge needs, it is not retried as it is bound to repeat
+func invalidCall(format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+// unexpectedResponse is the error of a call cartridge responded to with no
+// errors and no result, it is not retried as it is bound to repeat
+func unexpectedResponse(operation string) error {
+	return &Error{Message: fmt.Sprintf("%s: unexpected response", operation)}
+}
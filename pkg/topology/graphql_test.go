@@ -0,0 +1,45 @@
+package topology
+
+import "testing"
+
+func TestNewError(t *testing.T) {
+	cases := []struct {
+		className string
+		message   string
+		kind      error
+		transient bool
+	}{
+		{className: "PatchClusterwideError", message: "Cluster isn't bootstrapped yet", kind: ErrTopologyIsDown, transient: true},
+		{className: "PatchClusterwideError", message: "cartridge.patch_clusterwide is already running", transient: true},
+		{className: "PatchClusterwideError", message: "Invalid config"},
+		{className: "Editing cluster topology failed", message: `Server "storage-0-0:3301" is already joined`, kind: ErrAlreadyJoined},
+		{className: "Editing cluster topology failed", message: `Server "aaaaaaaa-aaaa-4000-b000-000000000001" not in clusterwide config`, kind: ErrAlreadyExpelled},
+		{className: "Editing cluster topology failed", message: `Replicaset "aaaaaaaa-0000-4000-b000-000000000000" does not exist`},
+		{className: "BootstrapError", message: "Sharding is already bootstrapped", kind: ErrAlreadyBootstrapped},
+		{className: "BootstrapError", message: "Sharding config is empty"},
+		{className: "Prepare2pcError", message: "Two-phase commit is locked", transient: true},
+		{className: "Commit2pcError", message: "Commit failed", transient: true},
+		{className: "NetboxConnectError", message: `"storage-0-1:3301": Connection refused`, transient: true},
+		{className: "NetboxCallError", message: "Peer closed", transient: true},
+		{className: "Invalid cluster topology config", message: `Server "storage-0-0:3301" is the leader and can't be expelled`},
+		// messages are matched only within the classes they are known for
+		{className: "Invalid cluster topology config", message: "Server is already joined"},
+		{message: "Cluster isn't bootstrapped yet"},
+		{message: `Cannot query field "foo" on type "Query"`},
+	}
+
+	for i, c := range cases {
+		respErr := &ResponseError{Message: c.message}
+		if c.className != "" {
+			respErr.Extensions = &ErrorExtensions{ClassName: c.className}
+		}
+
+		e := newError(respErr)
+		if e.Unwrap() != c.kind {
+			t.Errorf("%d: %s: expected kind %v, got %v", i, e, c.kind, e.Unwrap())
+		}
+		if e.Transient() != c.transient {
+			t.Errorf("%d: %s: expected transient %t, got %t", i, e, c.transient, e.Transient())
+		}
+	}
+}
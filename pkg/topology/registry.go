@@ -0,0 +1,65 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultProvider is the backend of clusters which do not choose one
+const DefaultProvider = CartridgeProvider
+
+// Factory builds a backend from the config
+type Factory func(config *Config) (TopologyService, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]Factory)
+)
+
+// Register makes a backend available by the provider name,
+// it panics when the name is taken
+func Register(provider string, factory Factory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if factory == nil {
+		panic("topology: Register factory is nil")
+	}
+	if _, ok := backends[provider]; ok {
+		panic(fmt.Sprintf("topology: Register called twice for provider %q", provider))
+	}
+
+	backends[provider] = factory
+}
+
+// New builds the backend of the provider, the default one if empty
+func New(provider string, opts ...Option) (TopologyService, error) {
+	if provider == "" {
+		provider = DefaultProvider
+	}
+
+	backendsMu.RLock()
+	factory, ok := backends[provider]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown topology provider %q", provider)
+	}
+
+	return factory(NewConfig(opts...))
+}
+
+// Providers lists registered provider names
+func Providers() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	providers := []string{}
+	for provider := range backends {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	return providers
+}
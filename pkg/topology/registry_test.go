@@ -0,0 +1,44 @@
+package topology
+
+import (
+	"testing"
+)
+
+type stubService struct {
+	TopologyService
+	config *Config
+}
+
+func TestRegistry(t *testing.T) {
+	Register("stub", func(config *Config) (TopologyService, error) {
+		return &stubService{config: config}, nil
+	})
+	defer func() {
+		backendsMu.Lock()
+		delete(backends, "stub")
+		backendsMu.Unlock()
+	}()
+
+	s, err := New("stub", WithTopologyEndpoint("http://storage-0-0:8081/admin/api"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	stub, ok := s.(*stubService)
+	if !ok || stub.config.Endpoint != "http://storage-0-0:8081/admin/api" || stub.config.Timeouts != DefaultTimeouts {
+		t.Fatalf("expected stub backend built from options, got %+v", s)
+	}
+
+	if s, err := New(""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if _, ok := s.(*BuiltInTopologyService); !ok {
+		t.Fatalf("expected cartridge backend by default, got %T", s)
+	}
+
+	if _, err := New("unknown"); err == nil {
+		t.Fatalf("expected unknown provider to fail")
+	}
+
+	if providers := Providers(); len(providers) != 2 || providers[0] != CartridgeProvider || providers[1] != "stub" {
+		t.Fatalf("unexpected providers %v", providers)
+	}
+}
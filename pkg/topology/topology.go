@@ -1,11 +1,131 @@
 package topology
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 )
 
-// TopologyService .
+// TopologyService manages the topology of a cluster through one of its
+// instances, see Register for plugging in backends
 type TopologyService interface {
-	Join(p *corev1.Pod) error
-	Expel(p *corev1.Pod) error
+	Join(ctx context.Context, p *corev1.Pod) error
+	Expel(ctx context.Context, p *corev1.Pod) error
+	// ApplyTopology creates replicasets, joins instances and edits
+	// replicasets, either all of the changes are applied or none
+	ApplyTopology(ctx context.Context, replicasets []*EditReplicasetInput) error
+	SetWeight(ctx context.Context, replicasetUUID string, weight string) error
+	// Promote makes the instance a master of the replicaset
+	Promote(ctx context.Context, replicasetUUID string, instanceUUID string, stateful bool) error
+	BootstrapVshard(ctx context.Context) error
+
+	GetFailoverParams(ctx context.Context) (*FailoverParams, error)
+	SetFailoverParams(ctx context.Context, params *FailoverParams) error
+
+	// GetSelf tells how the instance managed through sees itself
+	GetSelf(ctx context.Context) (*Self, error)
+	// GetKnownRoles lists roles the application provides
+	// along with the ones each of them depends on
+	GetKnownRoles(ctx context.Context) ([]*KnownRole, error)
+	GetServerStat(ctx context.Context) (ServerStatData, error)
+	GetReplicaSetList(ctx context.Context) (ReplicasetListResponse, error)
+
+	// Failed returns the error of the first call which did not reach
+	// the instance or failed on its side, nil if there was none
+	Failed() error
+}
+
+// MemtxService is implemented by backends which can grow memtx_memory of
+// running instances. It is not a part of cartridge, the application has to
+// register the set_memtx_memory mutation, ErrMemtxNotSupported is returned
+// when it does not
+type MemtxService interface {
+	SetMemtxMemory(ctx context.Context, instanceUUID string, memtxMemory int64) error
+}
+
+// Timeouts bound calls by operation, the context a call is made
+// with may end it earlier
+type Timeouts struct {
+	// Call bounds queries and small changes
+	Call time.Duration
+	// Join bounds join_server which waits for the instance to be configured
+	Join time.Duration
+	// EditTopology bounds edit_topology which waits for every joined
+	// instance to apply the new config
+	EditTopology time.Duration
+	// BootstrapVshard bounds bootstrap_vshard which distributes buckets
+	BootstrapVshard time.Duration
+}
+
+// DefaultTimeouts are used for operations WithTimeouts leaves unset
+var DefaultTimeouts = Timeouts{
+	Call:            5 * time.Second,
+	Join:            10 * time.Second,
+	EditTopology:    60 * time.Second,
+	BootstrapVshard: 60 * time.Second,
+}
+
+// Config is what a backend is built from
+type Config struct {
+	// Endpoint is the URL of the instance API calls are made to
+	Endpoint string
+	// AdvertiseURI derives the URI of a joining instance from its pod
+	AdvertiseURI func(pod *corev1.Pod) string
+	// HTTPClient is the client calls are made with
+	HTTPClient *http.Client
+	Timeouts   Timeouts
+}
+
+// NewConfig applies options over defaults
+func NewConfig(opts ...Option) *Config {
+	config := &Config{HTTPClient: defaultHTTPClient, Timeouts: DefaultTimeouts}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// Option .
+type Option func(c *Config)
+
+// WithTopologyEndpoint .
+func WithTopologyEndpoint(url string) Option {
+	return func(c *Config) {
+		c.Endpoint = url
+	}
+}
+
+// WithAdvertiseURI sets how the URI of a joining instance is derived from its pod
+func WithAdvertiseURI(f func(pod *corev1.Pod) string) Option {
+	return func(c *Config) {
+		c.AdvertiseURI = f
+	}
+}
+
+// WithHTTPClient sets the client admin calls are made with, see NewHTTPClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.HTTPClient = client
+	}
+}
+
+// WithTimeouts sets deadlines of admin calls, zero ones are left default
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(c *Config) {
+		if timeouts.Call > 0 {
+			c.Timeouts.Call = timeouts.Call
+		}
+		if timeouts.Join > 0 {
+			c.Timeouts.Join = timeouts.Join
+		}
+		if timeouts.EditTopology > 0 {
+			c.Timeouts.EditTopology = timeouts.EditTopology
+		}
+		if timeouts.BootstrapVshard > 0 {
+			c.Timeouts.BootstrapVshard = timeouts.BootstrapVshard
+		}
+	}
 }
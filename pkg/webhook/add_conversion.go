@@ -0,0 +1,10 @@
+package webhook
+
+import (
+	"github.com/tarantool/tarantool-operator/pkg/webhook/conversion"
+)
+
+func init() {
+	// AddToServerFuncs is a list of functions to create webhooks and add them to a webhook server.
+	AddToServerFuncs = append(AddToServerFuncs, conversion.Add)
+}
@@ -0,0 +1,273 @@
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var log = logf.Log.WithName("webhook_conversion")
+
+// Path is the webhook server path conversion requests are served at
+const Path = "/convert"
+
+// caCertName is the CA certificate file the webhook server provisions in its CertDir
+const caCertName = "ca-cert.pem"
+
+// crdNames are CRDs served in several versions
+var crdNames = []string{
+	"roles.tarantool.io",
+	"replicasettemplates.tarantool.io",
+}
+
+// Add registers the conversion handler in the webhook server and points
+// multi-version CRDs to it once the server certificate is provisioned
+func Add(mgr manager.Manager, srv *webhook.Server) ([]webhook.Webhook, error) {
+	if err := apiextensionsv1beta1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	// CRDs are cluster scoped, do not start a cache for them
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	srv.Handle(Path, &Handler{})
+
+	if err := mgr.Add(&crdInjector{client: c, server: srv}); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Handler serves ConversionReview requests between v1alpha1 and v1alpha2
+type Handler struct{}
+
+// ServeHTTP .
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if review.Request == nil {
+		http.Error(w, "conversion request is empty", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = Review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Error(err, "failed to write conversion response")
+	}
+}
+
+// Review converts all objects of the request, any failure fails the whole request
+func Review(req *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+	resp := &apiextensionsv1beta1.ConversionResponse{
+		UID: req.UID,
+	}
+
+	for _, obj := range req.Objects {
+		converted, err := Convert(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// Convert converts a single serialized object to desiredAPIVersion through the v1alpha1 storage version
+func Convert(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	switch typeMeta.Kind {
+	case "Role":
+		hub := &v1alpha1.Role{}
+		if err := decode(raw, typeMeta.APIVersion, hub, &v1alpha2.Role{}); err != nil {
+			return nil, err
+		}
+		return encode(hub, desiredAPIVersion, typeMeta.Kind, &v1alpha2.Role{})
+	case "ReplicasetTemplate":
+		hub := &v1alpha1.ReplicasetTemplate{}
+		if err := decode(raw, typeMeta.APIVersion, hub, &v1alpha2.ReplicasetTemplate{}); err != nil {
+			return nil, err
+		}
+		return encode(hub, desiredAPIVersion, typeMeta.Kind, &v1alpha2.ReplicasetTemplate{})
+	}
+
+	return nil, fmt.Errorf("conversion of kind %q is not supported", typeMeta.Kind)
+}
+
+func decode(raw []byte, apiVersion string, hub runtime.Object, spoke runtime.Object) error {
+	switch apiVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		return json.Unmarshal(raw, hub)
+	case v1alpha2.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, spoke); err != nil {
+			return err
+		}
+		return convertTo(spoke, hub)
+	}
+
+	return fmt.Errorf("unknown api version %q", apiVersion)
+}
+
+func encode(hub runtime.Object, apiVersion string, kind string, spoke runtime.Object) ([]byte, error) {
+	var out runtime.Object
+
+	switch apiVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		out = hub
+	case v1alpha2.SchemeGroupVersion.String():
+		if err := convertFrom(spoke, hub); err != nil {
+			return nil, err
+		}
+		out = spoke
+	default:
+		return nil, fmt.Errorf("unknown api version %q", apiVersion)
+	}
+
+	out.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(apiVersion, kind))
+
+	return json.Marshal(out)
+}
+
+func convertTo(spoke runtime.Object, hub runtime.Object) error {
+	switch s := spoke.(type) {
+	case *v1alpha2.Role:
+		return s.ConvertTo(hub.(*v1alpha1.Role))
+	case *v1alpha2.ReplicasetTemplate:
+		return s.ConvertTo(hub.(*v1alpha1.ReplicasetTemplate))
+	}
+
+	return fmt.Errorf("unsupported type %T", spoke)
+}
+
+func convertFrom(spoke runtime.Object, hub runtime.Object) error {
+	switch s := spoke.(type) {
+	case *v1alpha2.Role:
+		return s.ConvertFrom(hub.(*v1alpha1.Role))
+	case *v1alpha2.ReplicasetTemplate:
+		return s.ConvertFrom(hub.(*v1alpha1.ReplicasetTemplate))
+	}
+
+	return fmt.Errorf("unsupported type %T", spoke)
+}
+
+// crdInjector switches multi-version CRDs to webhook conversion served by
+// the webhook server, it waits for the server to provision its CA certificate
+type crdInjector struct {
+	client client.Client
+	server *webhook.Server
+}
+
+// Start .
+func (c *crdInjector) Start(stop <-chan struct{}) error {
+	var caBundle []byte
+	err := wait.PollUntil(time.Second, func() (bool, error) {
+		ca, err := ioutil.ReadFile(path.Join(c.server.CertDir, caCertName))
+		if err != nil {
+			return false, nil
+		}
+		caBundle = ca
+		return true, nil
+	}, stop)
+	if err != nil {
+		return nil
+	}
+
+	clientConfig := c.clientConfig(caBundle)
+	for _, name := range crdNames {
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		if err := c.client.Get(context.TODO(), types.NamespacedName{Name: name}, crd); err != nil {
+			log.Error(err, "failed to get CRD", "crd", name)
+			continue
+		}
+
+		crd.Spec.Conversion = &apiextensionsv1beta1.CustomResourceConversion{
+			Strategy:            apiextensionsv1beta1.WebhookConverter,
+			WebhookClientConfig: clientConfig,
+		}
+
+		// conversion webhooks need kubernetes 1.15 or the
+		// CustomResourceWebhookConversion feature gate, keep running without them
+		if err := c.client.Update(context.TODO(), crd); err != nil {
+			log.Error(err, "failed to enable CRD conversion webhook", "crd", name)
+			continue
+		}
+		log.Info("CRD conversion webhook enabled", "crd", name)
+	}
+
+	return nil
+}
+
+func (c *crdInjector) clientConfig(caBundle []byte) *apiextensionsv1beta1.WebhookClientConfig {
+	cc := &apiextensionsv1beta1.WebhookClientConfig{
+		CABundle: caBundle,
+	}
+
+	if c.server.BootstrapOptions != nil && c.server.Service != nil {
+		p := Path
+		cc.Service = &apiextensionsv1beta1.ServiceReference{
+			Namespace: c.server.Service.Namespace,
+			Name:      c.server.Service.Name,
+			Path:      &p,
+		}
+		return cc
+	}
+
+	host := "localhost"
+	if c.server.BootstrapOptions != nil && c.server.Host != nil {
+		host = *c.server.Host
+	}
+	u := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(host, strconv.Itoa(int(c.server.Port))),
+		Path:   Path,
+	}
+	s := u.String()
+	cc.URL = &s
+
+	return cc
+}
@@ -0,0 +1,30 @@
+package defaulting
+
+import (
+	"context"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type clusterDefaulter struct{}
+
+func (d *clusterDefaulter) newObject(version string) runtime.Object {
+	return newVersioned(version, &v1alpha1.Cluster{}, nil)
+}
+
+func (d *clusterDefaulter) setDefaults(ctx context.Context, c client.Client, obj runtime.Object) error {
+	SetClusterDefaults(obj.(*v1alpha1.Cluster))
+	return nil
+}
+
+// SetClusterDefaults stores the domain and ports the operator resolves
+// for the Cluster, so that changing operator flags later does not move
+// advertise URIs of its instances
+func SetClusterDefaults(cluster *v1alpha1.Cluster) {
+	cluster.Spec.Domain = tarantool.GetClusterDomain(cluster)
+	cluster.Spec.BinaryPort = tarantool.GetBinaryPort(cluster)
+	cluster.Spec.HTTPPort = tarantool.GetHTTPPort(cluster)
+}
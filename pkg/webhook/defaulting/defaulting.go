@@ -0,0 +1,139 @@
+package defaulting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2"
+	"github.com/tarantool/tarantool-operator/pkg/webhook/conversion"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// defaulter sets defaults on the v1alpha1 form of an object
+type defaulter interface {
+	setDefaults(ctx context.Context, c client.Client, obj runtime.Object) error
+	newObject(version string) runtime.Object
+}
+
+// Add builds mutating webhooks which store operator defaults in Cluster, Role and ReplicasetTemplate
+func Add(mgr manager.Manager, srv *webhook.Server) ([]webhook.Webhook, error) {
+	createUpdate := []admissionregistrationv1beta1.OperationType{
+		admissionregistrationv1beta1.Create,
+		admissionregistrationv1beta1.Update,
+	}
+
+	// Cluster defaults come from operator flags, they are stored once on
+	// create and are not refreshed when the flags change
+	resources := []struct {
+		resource   string
+		versions   []string
+		operations []admissionregistrationv1beta1.OperationType
+		defaulter  defaulter
+	}{
+		{"clusters", []string{"v1alpha1"}, []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create}, &clusterDefaulter{}},
+		{"roles", []string{"v1alpha1", "v1alpha2"}, createUpdate, &roleDefaulter{}},
+		{"replicasettemplates", []string{"v1alpha1", "v1alpha2"}, createUpdate, &replicasetTemplateDefaulter{}},
+	}
+
+	webhooks := []webhook.Webhook{}
+	for _, r := range resources {
+		wh, err := builder.NewWebhookBuilder().
+			Name(fmt.Sprintf("default-%s.tarantool.io", r.resource)).
+			Path(fmt.Sprintf("/default-%s", r.resource)).
+			Mutating().
+			Rules(admissionregistrationv1beta1.RuleWithOperations{
+				Operations: r.operations,
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{v1alpha1.SchemeGroupVersion.Group},
+					APIVersions: r.versions,
+					Resources:   []string{r.resource},
+				},
+			}).
+			FailurePolicy(admissionregistrationv1beta1.Ignore).
+			WithManager(mgr).
+			Handlers(&handler{defaulter: r.defaulter}).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	return webhooks, nil
+}
+
+// handler applies defaults to the v1alpha1 form of the object and patches
+// the object in the version it was sent in
+type handler struct {
+	client    client.Client
+	defaulter defaulter
+}
+
+// InjectClient .
+func (h *handler) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+// Handle .
+func (h *handler) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	raw := req.AdmissionRequest.Object.Raw
+	version := req.AdmissionRequest.Kind.Version
+
+	original := h.defaulter.newObject(version)
+	if original == nil {
+		return admission.ErrorResponse(http.StatusBadRequest, fmt.Errorf("unknown api version %q", version))
+	}
+	if err := json.Unmarshal(raw, original); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	hub := h.defaulter.newObject(v1alpha1.SchemeGroupVersion.Version)
+	hubRaw, err := conversion.Convert(raw, v1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(hubRaw, hub); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	if err := h.defaulter.setDefaults(ctx, h.client, hub); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	if hubRaw, err = json.Marshal(hub); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+	currentRaw, err := conversion.Convert(hubRaw, v1alpha1.SchemeGroupVersion.Group+"/"+version)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	current := h.defaulter.newObject(version)
+	if err := json.Unmarshal(currentRaw, current); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponse(original, current)
+}
+
+func newVersioned(version string, v1 runtime.Object, v2 runtime.Object) runtime.Object {
+	switch version {
+	case v1alpha1.SchemeGroupVersion.Version:
+		return v1
+	case v1alpha2.SchemeGroupVersion.Version:
+		return v2
+	}
+
+	return nil
+}
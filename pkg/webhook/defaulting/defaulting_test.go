@@ -0,0 +1,98 @@
+package defaulting
+
+import (
+	"testing"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetRoleDefaults(t *testing.T) {
+	role := &v1alpha1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"tarantool.io/role": "storage"},
+		},
+	}
+
+	SetRoleDefaults(role, nil)
+	if role.Annotations["tarantool.io/replicaset-weight"] != "100" {
+		t.Fatalf("expected default weight, got %v", role.Annotations)
+	}
+	if _, ok := role.Annotations["tarantool.io/vshardGroupName"]; ok {
+		t.Fatalf("vshard group must not be set when templates do not use vshard groups")
+	}
+
+	template := v1alpha1.ReplicasetTemplate{
+		Spec: &appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"tarantool.io/useVshardGroups": "1"},
+				},
+			},
+		},
+	}
+
+	role.Annotations["tarantool.io/replicaset-weight"] = "10"
+	SetRoleDefaults(role, []v1alpha1.ReplicasetTemplate{template})
+	if role.Annotations["tarantool.io/replicaset-weight"] != "10" {
+		t.Fatalf("weight set by user must be kept, got %v", role.Annotations)
+	}
+	if role.Annotations["tarantool.io/vshardGroupName"] != "storage" {
+		t.Fatalf("vshard group must fall back to role label, got %v", role.Annotations)
+	}
+}
+
+func TestSetReplicasetTemplateDefaults(t *testing.T) {
+	template := &v1alpha1.ReplicasetTemplate{
+		Spec: &appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "log-shipper"}, {Name: "tarantool"}},
+				},
+			},
+		},
+	}
+
+	SetReplicasetTemplateDefaults(template)
+
+	if *template.Spec.Replicas != 1 {
+		t.Fatalf("expected 1 replica, got %d", *template.Spec.Replicas)
+	}
+	if template.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		t.Fatalf("update strategy set by user must be kept")
+	}
+	for _, container := range template.Spec.Template.Spec.Containers {
+		if container.SecurityContext != nil {
+			t.Fatalf("security context of %s must be left to the template", container.Name)
+		}
+	}
+}
+
+func TestSetClusterDefaults(t *testing.T) {
+	defer func(domain string) { tarantool.DefaultClusterDomain = domain }(tarantool.DefaultClusterDomain)
+	tarantool.DefaultClusterDomain = "k8s.example.com"
+
+	cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{BinaryPort: 3302}}
+	SetClusterDefaults(cluster)
+
+	if cluster.Spec.Domain != "k8s.example.com" {
+		t.Fatalf("expected domain of operator flags, got %q", cluster.Spec.Domain)
+	}
+	if cluster.Spec.BinaryPort != 3302 {
+		t.Fatalf("binary port set by user must be kept, got %d", cluster.Spec.BinaryPort)
+	}
+	if cluster.Spec.HTTPPort != tarantool.DefaultHTTPPort {
+		t.Fatalf("expected default http port, got %d", cluster.Spec.HTTPPort)
+	}
+
+	// stored values do not follow operator flags
+	tarantool.DefaultClusterDomain = "cluster.local"
+	SetClusterDefaults(cluster)
+	if cluster.Spec.Domain != "k8s.example.com" {
+		t.Fatalf("stored domain must be kept, got %q", cluster.Spec.Domain)
+	}
+}
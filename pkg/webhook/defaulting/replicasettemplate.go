@@ -0,0 +1,38 @@
+package defaulting
+
+import (
+	"context"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type replicasetTemplateDefaulter struct{}
+
+func (d *replicasetTemplateDefaulter) newObject(version string) runtime.Object {
+	return newVersioned(version, &v1alpha1.ReplicasetTemplate{}, &v1alpha2.ReplicasetTemplate{})
+}
+
+func (d *replicasetTemplateDefaulter) setDefaults(ctx context.Context, c client.Client, obj runtime.Object) error {
+	SetReplicasetTemplateDefaults(obj.(*v1alpha1.ReplicasetTemplate))
+	return nil
+}
+
+// SetReplicasetTemplateDefaults stores StatefulSet settings the operator applies by default
+func SetReplicasetTemplateDefaults(template *v1alpha1.ReplicasetTemplate) {
+	if template.Spec == nil {
+		return
+	}
+
+	if template.Spec.Replicas == nil {
+		replicas := int32(1)
+		template.Spec.Replicas = &replicas
+	}
+
+	if template.Spec.UpdateStrategy.Type == "" {
+		template.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	}
+}
@@ -0,0 +1,68 @@
+package defaulting
+
+import (
+	"context"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type roleDefaulter struct{}
+
+func (d *roleDefaulter) newObject(version string) runtime.Object {
+	return newVersioned(version, &v1alpha1.Role{}, &v1alpha2.Role{})
+}
+
+func (d *roleDefaulter) setDefaults(ctx context.Context, c client.Client, obj runtime.Object) error {
+	role := obj.(*v1alpha1.Role)
+
+	templateList := &v1alpha1.ReplicasetTemplateList{}
+	if role.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(role.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		if err := c.List(ctx, &client.ListOptions{Namespace: role.GetNamespace(), LabelSelector: selector}, templateList); err != nil {
+			return err
+		}
+	}
+
+	SetRoleDefaults(role, templateList.Items)
+	return nil
+}
+
+// SetRoleDefaults stores replicaset weight and, when templates use vshard
+// groups, the vshard group the operator would otherwise pick silently
+func SetRoleDefaults(role *v1alpha1.Role, templates []v1alpha1.ReplicasetTemplate) {
+	annotations := role.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if _, ok := annotations[v1alpha1.ReplicasetWeightAnnotation]; !ok {
+		annotations[v1alpha1.ReplicasetWeightAnnotation] = "100"
+	}
+
+	if _, ok := annotations[v1alpha1.VshardGroupNameAnnotation]; !ok && useVshardGroups(templates) {
+		if group, ok := role.GetLabels()[v1alpha1.VshardGroupNameAnnotation]; ok {
+			annotations[v1alpha1.VshardGroupNameAnnotation] = group
+		} else if group, ok := role.GetLabels()["tarantool.io/role"]; ok {
+			annotations[v1alpha1.VshardGroupNameAnnotation] = group
+		}
+	}
+
+	role.SetAnnotations(annotations)
+}
+
+func useVshardGroups(templates []v1alpha1.ReplicasetTemplate) bool {
+	for _, template := range templates {
+		if template.Spec != nil && template.Spec.Template.GetLabels()["tarantool.io/useVshardGroups"] == "1" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	"github.com/tarantool/tarantool-operator/pkg/topology"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type clusterValidator struct{}
+
+func (v *clusterValidator) newObject() runtime.Object {
+	return &v1alpha1.Cluster{}
+}
+
+func (v *clusterValidator) validate(ctx context.Context, c client.Client, obj runtime.Object, old runtime.Object) field.ErrorList {
+	if old == nil {
+		return ValidateCluster(obj.(*v1alpha1.Cluster), nil)
+	}
+
+	return ValidateCluster(obj.(*v1alpha1.Cluster), old.(*v1alpha1.Cluster))
+}
+
+// ValidateCluster checks Cluster spec, old is nil on create
+func ValidateCluster(cluster *v1alpha1.Cluster, old *v1alpha1.Cluster) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	errs = append(errs, validateSelector(cluster.Spec.Selector, specPath.Child("selector"))...)
+
+	if old != nil && !reflect.DeepEqual(cluster.Spec.Selector, old.Spec.Selector) {
+		errs = append(errs, field.Forbidden(specPath.Child("selector"), "cluster selector is immutable"))
+	}
+
+	// instances are joined by their advertise URI, so it must not move,
+	// resolved values are compared as Clusters created before defaults
+	// were stored on create leave the fields empty
+	if old != nil {
+		if tarantool.GetClusterDomain(cluster) != tarantool.GetClusterDomain(old) {
+			errs = append(errs, field.Forbidden(specPath.Child("domain"), "cluster domain is immutable"))
+		}
+		if tarantool.GetBinaryPort(cluster) != tarantool.GetBinaryPort(old) {
+			errs = append(errs, field.Forbidden(specPath.Child("binaryPort"), "binary port is immutable"))
+		}
+	}
+
+	if cluster.Spec.Failover != nil {
+		errs = append(errs, validateFailover(cluster.Spec.Failover, specPath.Child("failover"))...)
+	}
+
+	if cluster.Spec.AdminAPI != nil && cluster.Spec.AdminAPI.Auth != nil {
+		ref := cluster.Spec.AdminAPI.Auth.PasswordSecretRef
+		if ref.Name == "" || ref.Key == "" {
+			errs = append(errs, field.Required(specPath.Child("adminAPI", "auth", "passwordSecretRef"), "password secret name and key are required"))
+		}
+	}
+
+	if cluster.Spec.Topology != nil && cluster.Spec.Topology.Provider != "" {
+		providers := topology.Providers()
+		registered := false
+		for _, provider := range providers {
+			registered = registered || provider == cluster.Spec.Topology.Provider
+		}
+		if !registered {
+			errs = append(errs, field.NotSupported(specPath.Child("topology", "provider"), cluster.Spec.Topology.Provider, providers))
+		}
+	}
+
+	return errs
+}
+
+func validateFailover(failover *v1alpha1.FailoverSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	switch failover.Mode {
+	case "disabled", "eventual":
+		return errs
+	case "stateful":
+	default:
+		return append(errs, field.NotSupported(path.Child("mode"), failover.Mode, []string{"disabled", "eventual", "stateful"}))
+	}
+
+	switch failover.StateProvider {
+	case "tarantool":
+		if failover.Tarantool == nil {
+			errs = append(errs, field.Required(path.Child("tarantool"), "tarantool state provider needs its parameters"))
+		} else if failover.Tarantool.URI == "" {
+			errs = append(errs, field.Required(path.Child("tarantool", "uri"), "stateboard uri is required"))
+		}
+	case "etcd2":
+		if failover.Etcd2 == nil {
+			errs = append(errs, field.Required(path.Child("etcd2"), "etcd2 state provider needs its parameters"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("stateProvider"), failover.StateProvider, []string{"tarantool", "etcd2"}))
+	}
+
+	return errs
+}
+
+// validateSelector requires a parseable selector which does not match everything
+func validateSelector(selector *metav1.LabelSelector, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if selector == nil {
+		return append(errs, field.Required(path, "selector is required"))
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return append(errs, field.Invalid(path, selector, err.Error()))
+	}
+
+	if s.Empty() {
+		errs = append(errs, field.Invalid(path, selector, "empty selector matches everything"))
+	}
+
+	return errs
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2"
+	"github.com/tarantool/tarantool-operator/pkg/tarantool"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type replicasetTemplateValidator struct{}
+
+func (v *replicasetTemplateValidator) newObject() runtime.Object {
+	return &v1alpha1.ReplicasetTemplate{}
+}
+
+func (v *replicasetTemplateValidator) validate(ctx context.Context, c client.Client, obj runtime.Object, old runtime.Object) field.ErrorList {
+	return ValidateReplicasetTemplate(obj.(*v1alpha1.ReplicasetTemplate))
+}
+
+// ValidateReplicasetTemplate checks that StatefulSets can be created from the template
+func ValidateReplicasetTemplate(template *v1alpha1.ReplicasetTemplate) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if template.Spec == nil {
+		return append(errs, field.Required(specPath, "StatefulSet spec is required"))
+	}
+
+	if template.Spec.Replicas != nil && *template.Spec.Replicas < 1 {
+		errs = append(errs, field.Invalid(specPath.Child("replicas"), *template.Spec.Replicas, "replicaset needs at least one instance"))
+	}
+
+	if len(template.Spec.Template.Spec.Containers) == 0 {
+		errs = append(errs, field.Required(specPath.Child("template", "spec", "containers"), "at least one container is required"))
+	}
+
+	selectorPath := specPath.Child("selector")
+	if selectorErrs := validateSelector(template.Spec.Selector, selectorPath); len(selectorErrs) > 0 {
+		errs = append(errs, selectorErrs...)
+	} else {
+		selector, _ := metav1.LabelSelectorAsSelector(template.Spec.Selector)
+		if !selector.Matches(labels.Set(template.Spec.Template.GetLabels())) {
+			errs = append(errs, field.Invalid(selectorPath, selector.String(), "selector does not match template labels"))
+		}
+	}
+
+	annotationsPath := specPath.Child("template", "metadata", "annotations")
+	if name, ok := template.Spec.Template.GetAnnotations()[tarantool.ContainerNameAnnotation]; ok {
+		if tarantool.ContainerIndex(template.Spec.Template.GetAnnotations(), &template.Spec.Template.Spec) < 0 {
+			errs = append(errs, field.Invalid(annotationsPath.Key(tarantool.ContainerNameAnnotation), name, "no container with this name"))
+		}
+	}
+	if val, ok := template.Spec.Template.GetAnnotations()[v1alpha1.RolesToAssignAnnotation]; ok {
+		if _, err := v1alpha2.ParseCartridgeRoles(val); err != nil {
+			errs = append(errs, field.Invalid(annotationsPath.Key(v1alpha1.RolesToAssignAnnotation), val, err.Error()))
+		}
+	}
+
+	if _, err := tarantool.GetMemtxOverhead(template.Spec.Template.GetAnnotations()); err != nil {
+		errs = append(errs, field.Invalid(annotationsPath.Key(tarantool.MemtxOverheadAnnotation), template.Spec.Template.GetAnnotations()[tarantool.MemtxOverheadAnnotation], err.Error()))
+	} else if idx := tarantool.ContainerIndex(template.Spec.Template.GetAnnotations(), &template.Spec.Template.Spec); idx >= 0 {
+		errs = append(errs, validateMemtxMemory(template.Spec.Template.GetAnnotations(), &template.Spec.Template.Spec.Containers[idx], specPath.Child("template", "spec", "containers").Index(idx))...)
+	}
+
+	return errs
+}
+
+// validateMemtxMemory checks that memtx_memory set in the container
+// environment leaves the configured overhead within the memory limit
+func validateMemtxMemory(annotations map[string]string, container *corev1.Container, containerPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	envPath := containerPath.Child("env")
+
+	memtx, ok, err := tarantool.GetMemtxMemory(container)
+	if err != nil {
+		return append(errs, field.Invalid(envPath, tarantool.MemtxMemoryEnv, "memtx_memory must be a number of bytes"))
+	}
+	if !ok {
+		return errs
+	}
+
+	capacity, limited, err := tarantool.GetMemtxCapacity(annotations, container)
+	if err == nil && limited && memtx > capacity {
+		errs = append(errs, field.Invalid(envPath, memtx, fmt.Sprintf("memtx_memory does not fit into memory limit with overhead, at most %d bytes are allowed", capacity)))
+	}
+
+	return errs
+}
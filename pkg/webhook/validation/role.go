@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha2"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type roleValidator struct{}
+
+func (v *roleValidator) newObject() runtime.Object {
+	return &v1alpha1.Role{}
+}
+
+func (v *roleValidator) validate(ctx context.Context, c client.Client, obj runtime.Object, old runtime.Object) field.ErrorList {
+	role := obj.(*v1alpha1.Role)
+
+	var oldRole *v1alpha1.Role
+	if old != nil {
+		oldRole = old.(*v1alpha1.Role)
+	}
+
+	errs := ValidateRole(role, oldRole)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// a role matching no templates never gets its StatefulSets, checked only
+	// when the selector changes so the operator can still update the Role
+	if oldRole == nil || !reflect.DeepEqual(role.Spec.Selector, oldRole.Spec.Selector) {
+		selector, _ := metav1.LabelSelectorAsSelector(role.Spec.Selector)
+		templateList := &v1alpha1.ReplicasetTemplateList{}
+		if err := c.List(ctx, &client.ListOptions{Namespace: role.GetNamespace(), LabelSelector: selector}, templateList); err != nil {
+			return append(errs, field.InternalError(field.NewPath("spec", "selector"), err))
+		}
+		if len(templateList.Items) == 0 {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "selector"), selector.String(), "no ReplicasetTemplate matches the selector"))
+		}
+	}
+
+	// instances join with their vshard group, it can't be changed afterwards
+	if oldRole != nil && GetVshardGroup(role) != GetVshardGroup(oldRole) {
+		stsList := &appsv1.StatefulSetList{}
+		if err := c.List(ctx, &client.ListOptions{Namespace: role.GetNamespace(), LabelSelector: labels.SelectorFromSet(oldRole.GetLabels())}, stsList); err != nil {
+			return append(errs, field.InternalError(field.NewPath("metadata", "annotations"), err))
+		}
+		if len(stsList.Items) > 0 {
+			errs = append(errs, field.Forbidden(field.NewPath("metadata", "annotations").Key(v1alpha1.VshardGroupNameAnnotation), "vshard group can't be changed once replicasets of the role are created"))
+		}
+	}
+
+	return errs
+}
+
+// ValidateRole checks Role spec and typed annotations, old is nil on create
+func ValidateRole(role *v1alpha1.Role, old *v1alpha1.Role) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	if len(role.GetLabels()) == 0 {
+		errs = append(errs, field.Required(field.NewPath("metadata", "labels"), "labels select StatefulSets of the role"))
+	}
+
+	if role.Spec.NumReplicasets == nil {
+		errs = append(errs, field.Required(specPath.Child("numReplicasets"), "number of replicasets is required"))
+	} else if *role.Spec.NumReplicasets < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("numReplicasets"), *role.Spec.NumReplicasets, "must be non-negative"))
+	}
+
+	errs = append(errs, validateSelector(role.Spec.Selector, specPath.Child("selector"))...)
+
+	annotations := role.GetAnnotations()
+	if val, ok := annotations[v1alpha1.RolesToAssignAnnotation]; ok {
+		if _, err := v1alpha2.ParseCartridgeRoles(val); err != nil {
+			errs = append(errs, field.Invalid(annotationsPath.Key(v1alpha1.RolesToAssignAnnotation), val, err.Error()))
+		}
+	}
+	if val, ok := annotations[v1alpha1.ReplicasetWeightAnnotation]; ok {
+		if weight, err := strconv.Atoi(val); err != nil || weight < 0 {
+			errs = append(errs, field.Invalid(annotationsPath.Key(v1alpha1.ReplicasetWeightAnnotation), val, "must be a non-negative integer"))
+		}
+	}
+	// checked only when set or changed, so the operator can still update
+	// Roles annotated before the mode was checked
+	if val, ok := annotations[v1alpha1.FailoverModeAnnotation]; ok && (old == nil || old.GetAnnotations()[v1alpha1.FailoverModeAnnotation] != val) {
+		switch val {
+		case "eventual", "stateful-tarantool":
+		case "stateful-etcd2":
+			if annotations[v1alpha1.FailoverSecretAnnotation] == "" {
+				errs = append(errs, field.Required(annotationsPath.Key(v1alpha1.FailoverSecretAnnotation), "Secret with etcd2 parameters is required by stateful-etcd2 failover mode"))
+			}
+		case "stateful-consul":
+			errs = append(errs, field.Invalid(annotationsPath.Key(v1alpha1.FailoverModeAnnotation), val, "cartridge has no consul state provider, use stateful-tarantool or stateful-etcd2"))
+		default:
+			errs = append(errs, field.NotSupported(annotationsPath.Key(v1alpha1.FailoverModeAnnotation), val, []string{"eventual", "stateful-tarantool", "stateful-etcd2"}))
+		}
+	}
+	for _, key := range []string{v1alpha1.AllRWAnnotation, v1alpha1.PrivilegedAnnotation} {
+		if val, ok := annotations[key]; ok {
+			if _, err := strconv.ParseBool(val); err != nil {
+				errs = append(errs, field.Invalid(annotationsPath.Key(key), val, "must be a boolean"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// GetVshardGroup returns vshard group Role replicasets join with
+func GetVshardGroup(role *v1alpha1.Role) string {
+	if group, ok := role.GetAnnotations()[v1alpha1.VshardGroupNameAnnotation]; ok {
+		return group
+	}
+	if group, ok := role.GetLabels()[v1alpha1.VshardGroupNameAnnotation]; ok {
+		return group
+	}
+
+	return role.GetLabels()["tarantool.io/role"]
+}
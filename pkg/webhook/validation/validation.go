@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	"github.com/tarantool/tarantool-operator/pkg/webhook/conversion"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// validator checks a create or update request, old is nil on create
+type validator interface {
+	validate(ctx context.Context, c client.Client, obj runtime.Object, old runtime.Object) field.ErrorList
+	newObject() runtime.Object
+}
+
+// Add builds validating webhooks for Cluster, Role and ReplicasetTemplate
+func Add(mgr manager.Manager, srv *webhook.Server) ([]webhook.Webhook, error) {
+	resources := []struct {
+		resource  string
+		versions  []string
+		validator validator
+	}{
+		{"clusters", []string{"v1alpha1"}, &clusterValidator{}},
+		{"roles", []string{"v1alpha1", "v1alpha2"}, &roleValidator{}},
+		{"replicasettemplates", []string{"v1alpha1", "v1alpha2"}, &replicasetTemplateValidator{}},
+	}
+
+	webhooks := []webhook.Webhook{}
+	for _, r := range resources {
+		wh, err := builder.NewWebhookBuilder().
+			Name(fmt.Sprintf("validate-%s.tarantool.io", r.resource)).
+			Path(fmt.Sprintf("/validate-%s", r.resource)).
+			Validating().
+			Rules(admissionregistrationv1beta1.RuleWithOperations{
+				Operations: []admissionregistrationv1beta1.OperationType{
+					admissionregistrationv1beta1.Create,
+					admissionregistrationv1beta1.Update,
+				},
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{v1alpha1.SchemeGroupVersion.Group},
+					APIVersions: r.versions,
+					Resources:   []string{r.resource},
+				},
+			}).
+			FailurePolicy(admissionregistrationv1beta1.Fail).
+			WithManager(mgr).
+			Handlers(&handler{validator: r.validator}).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	return webhooks, nil
+}
+
+// handler decodes request objects to the v1alpha1 storage version and runs the validator
+type handler struct {
+	client    client.Client
+	validator validator
+}
+
+// InjectClient .
+func (h *handler) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+// Handle .
+func (h *handler) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	obj := h.validator.newObject()
+	if err := decode(req.AdmissionRequest.Object.Raw, obj); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	var old runtime.Object
+	if len(req.AdmissionRequest.OldObject.Raw) > 0 {
+		old = h.validator.newObject()
+		if err := decode(req.AdmissionRequest.OldObject.Raw, old); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+	}
+
+	if errs := h.validator.validate(ctx, h.client, obj, old); len(errs) > 0 {
+		return admission.ValidationResponse(false, errs.ToAggregate().Error())
+	}
+
+	return admission.ValidationResponse(true, "")
+}
+
+// decode reads an object of any served version into its v1alpha1 form
+func decode(raw []byte, into runtime.Object) error {
+	converted, err := conversion.Convert(raw, v1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(converted, into)
+}
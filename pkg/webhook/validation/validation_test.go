@@ -0,0 +1,240 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarantool/tarantool-operator/pkg/apis/tarantool/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type validationTestCase struct {
+	name        string
+	errs        func() error
+	expectedErr string
+}
+
+func TestValidate(t *testing.T) {
+	replicas := int32(2)
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"tarantool.io/replicaset-template": "storage-template"}}
+
+	newRole := func() *v1alpha1.Role {
+		return &v1alpha1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"tarantool.io/role": "storage"},
+			},
+			Spec: v1alpha1.RoleSpec{
+				NumReplicasets: &replicas,
+				Selector:       selector,
+			},
+		}
+	}
+
+	newTemplate := func() *v1alpha1.ReplicasetTemplate {
+		return &v1alpha1.ReplicasetTemplate{
+			Spec: &appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tarantool.io/pod-template": "storage"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{"tarantool.io/pod-template": "storage"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "tarantool"}},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []validationTestCase{
+		{
+			name:        "valid role",
+			errs:        func() error { return ValidateRole(newRole(), nil).ToAggregate() },
+			expectedErr: "",
+		},
+		{
+			name: "role without numReplicasets",
+			errs: func() error {
+				role := newRole()
+				role.Spec.NumReplicasets = nil
+				return ValidateRole(role, nil).ToAggregate()
+			},
+			expectedErr: "spec.numReplicasets: Required value",
+		},
+		{
+			name: "role with broken rolesToAssign",
+			errs: func() error {
+				role := newRole()
+				role.Annotations = map[string]string{"tarantool.io/rolesToAssign": `["vshard-storage"`}
+				return ValidateRole(role, nil).ToAggregate()
+			},
+			expectedErr: "metadata.annotations[tarantool.io/rolesToAssign]: Invalid value",
+		},
+		{
+			name: "role with consul failover",
+			errs: func() error {
+				role := newRole()
+				role.Annotations = map[string]string{"tarantool.io/failoverMode": "stateful-consul"}
+				return ValidateRole(role, nil).ToAggregate()
+			},
+			expectedErr: "cartridge has no consul state provider",
+		},
+		{
+			name: "role with etcd2 failover and no secret",
+			errs: func() error {
+				role := newRole()
+				role.Annotations = map[string]string{"tarantool.io/failoverMode": "stateful-etcd2"}
+				return ValidateRole(role, nil).ToAggregate()
+			},
+			expectedErr: "metadata.annotations[tarantool.io/failoverSecret]: Required value",
+		},
+		{
+			name: "role keeps failover mode it was created with",
+			errs: func() error {
+				role := newRole()
+				role.Annotations = map[string]string{"tarantool.io/failoverMode": "stateful-consul"}
+				return ValidateRole(role, role.DeepCopy()).ToAggregate()
+			},
+			expectedErr: "",
+		},
+		{
+			name:        "valid template",
+			errs:        func() error { return ValidateReplicasetTemplate(newTemplate()).ToAggregate() },
+			expectedErr: "",
+		},
+		{
+			name: "template without containers",
+			errs: func() error {
+				template := newTemplate()
+				template.Spec.Template.Spec.Containers = nil
+				return ValidateReplicasetTemplate(template).ToAggregate()
+			},
+			expectedErr: "spec.template.spec.containers: Required value",
+		},
+		{
+			name: "template selector does not match pods",
+			errs: func() error {
+				template := newTemplate()
+				template.Spec.Template.Labels = nil
+				return ValidateReplicasetTemplate(template).ToAggregate()
+			},
+			expectedErr: "selector does not match template labels",
+		},
+		{
+			name: "template names a missing tarantool container",
+			errs: func() error {
+				template := newTemplate()
+				template.Spec.Template.Annotations = map[string]string{"tarantool.io/tarantoolContainerName": "storage"}
+				return ValidateReplicasetTemplate(template).ToAggregate()
+			},
+			expectedErr: "no container with this name",
+		},
+		{
+			name: "memtx memory exceeds memory limit",
+			errs: func() error {
+				template := newTemplate()
+				container := &template.Spec.Template.Spec.Containers[0]
+				container.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}
+				container.Env = []corev1.EnvVar{{Name: "TARANTOOL_MEMTX_MEMORY", Value: "268435456"}}
+				return ValidateReplicasetTemplate(template).ToAggregate()
+			},
+			expectedErr: "memtx_memory does not fit into memory limit",
+		},
+		{
+			name: "invalid memtx overhead ratio",
+			errs: func() error {
+				template := newTemplate()
+				template.Spec.Template.Annotations = map[string]string{"tarantool.io/memtxOverheadRatio": "1.5"}
+				return ValidateReplicasetTemplate(template).ToAggregate()
+			},
+			expectedErr: "overhead ratio must be in [0, 1)",
+		},
+		{
+			name: "cluster selector change",
+			errs: func() error {
+				old := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Selector: selector}}
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"a": "b"}}}}
+				return ValidateCluster(cluster, old).ToAggregate()
+			},
+			expectedErr: "cluster selector is immutable",
+		},
+		{
+			name: "cluster binary port change",
+			errs: func() error {
+				old := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Selector: selector}}
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Selector: selector, BinaryPort: 3302}}
+				return ValidateCluster(cluster, old).ToAggregate()
+			},
+			expectedErr: "binary port is immutable",
+		},
+		{
+			name: "cluster domain set to the default",
+			errs: func() error {
+				old := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Selector: selector}}
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{Selector: selector, Domain: "cluster.local", HTTPPort: 8082}}
+				return ValidateCluster(cluster, old).ToAggregate()
+			},
+		},
+		{
+			name: "admin api auth without password",
+			errs: func() error {
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{
+					Selector: selector,
+					AdminAPI: &v1alpha1.AdminAPISpec{Auth: &v1alpha1.AdminAuthSpec{Username: "admin"}},
+				}}
+				return ValidateCluster(cluster, nil).ToAggregate()
+			},
+			expectedErr: "spec.adminAPI.auth.passwordSecretRef: Required value",
+		},
+		{
+			name: "unknown topology provider",
+			errs: func() error {
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{
+					Selector: selector,
+					Topology: &v1alpha1.TopologySpec{Provider: "consul"},
+				}}
+				return ValidateCluster(cluster, nil).ToAggregate()
+			},
+			expectedErr: "spec.topology.provider: Unsupported value",
+		},
+		{
+			name: "cartridge topology provider",
+			errs: func() error {
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{
+					Selector: selector,
+					Topology: &v1alpha1.TopologySpec{Provider: "cartridge"},
+				}}
+				return ValidateCluster(cluster, nil).ToAggregate()
+			},
+		},
+		{
+			name: "stateful failover without state provider parameters",
+			errs: func() error {
+				cluster := &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{
+					Selector: selector,
+					Failover: &v1alpha1.FailoverSpec{Mode: "stateful", StateProvider: "etcd2"},
+				}}
+				return ValidateCluster(cluster, nil).ToAggregate()
+			},
+			expectedErr: "spec.failover.etcd2: Required value",
+		},
+	}
+
+	for _, c := range cases {
+		err := c.errs()
+		if c.expectedErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error %s", c.name, err.Error())
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+			t.Fatalf("%s: expected error %q, got %v", c.name, c.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// AddToServerFuncs is a list of functions to add handlers to the webhook Server,
+// admission webhooks they return are registered at once
+var AddToServerFuncs []func(manager.Manager, *webhook.Server) ([]webhook.Webhook, error)
+
+// AddToManager creates the webhook Server, adds all webhooks to it and the Server to the Manager
+func AddToManager(m manager.Manager, options webhook.ServerOptions) error {
+	srv, err := webhook.NewServer("tarantool-operator-webhook", m, options)
+	if err != nil {
+		return err
+	}
+
+	webhooks := []webhook.Webhook{}
+	for _, f := range AddToServerFuncs {
+		whs, err := f(m, srv)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, whs...)
+	}
+
+	return srv.Register(webhooks...)
+}